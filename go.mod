@@ -2,4 +2,10 @@ module instacli
 
 go 1.24.1
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	github.com/mattn/go-sqlite3 v1.14.33
+	golang.org/x/term v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.31.0 // indirect
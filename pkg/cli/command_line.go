@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"instacli/pkg/cli/commands"
+)
+
+// commandLine holds the global options given on the command line
+type commandLine struct {
+	help           bool
+	output         bool
+	outputJSON     bool
+	nonInteractive bool
+	debug          bool
+	emitSchema     bool
+	test           bool
+	skipExamples   bool
+	strict         bool
+	filter         string
+	reportFiles    map[string]*string
+	// userInteraction replaces the default way of asking for input if it is set
+	userInteraction commands.UserInteraction
+	// interrupt is done when the user presses Ctrl-C
+	interrupt context.Context
+}
+
+// RunCommandLine runs the cli command with the given arguments, without the program name. Relative paths are
+// resolved against workingDir, or the current directory if it is empty. Everything is printed to out.
+// It returns the exit code.
+func RunCommandLine(args []string, workingDir string, out io.Writer) int {
+	return runCommandLine(args, workingDir, out, nil)
+}
+
+// runCommandLine runs the cli command like RunCommandLine. Scripts ask for input with ui if it is not nil.
+func runCommandLine(args []string, workingDir string, out io.Writer, ui commands.UserInteraction) int {
+	options, err := LoadOptions()
+	if err != nil {
+		fmt.Fprintf(out, "Error loading options: %v\n", err)
+		return 1
+	}
+
+	flags := flag.NewFlagSet("cli", flag.ContinueOnError)
+	flags.SetOutput(out)
+	cl := &commandLine{reportFiles: map[string]*string{}, userInteraction: ui}
+	for _, opt := range options {
+		switch name := opt.Name; name {
+		case "help":
+			cl.boolVar(flags, &cl.help, name, opt)
+		case "output":
+			cl.boolVar(flags, &cl.output, name, opt)
+		case "output-json":
+			cl.boolVar(flags, &cl.outputJSON, name, opt)
+		case "non-interactive":
+			cl.boolVar(flags, &cl.nonInteractive, name, opt)
+		case "debug":
+			cl.boolVar(flags, &cl.debug, name, opt)
+		case "emit-schema":
+			flags.BoolVar(&cl.emitSchema, name, opt.Default, opt.Description)
+		case "test":
+			flags.BoolVar(&cl.test, name, opt.Default, opt.Description)
+		case "skip-examples":
+			flags.BoolVar(&cl.skipExamples, name, opt.Default, opt.Description)
+		case "strict":
+			flags.BoolVar(&cl.strict, name, opt.Default, opt.Description)
+		case "filter":
+			flags.StringVar(&cl.filter, name, "", opt.Description)
+		case "junit", "tap":
+			cl.reportFiles[name] = flags.String(name, "", opt.Description)
+		}
+	}
+	flags.Usage = func() { fmt.Fprint(out, options.FormatHelp()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+	if len(args) == 0 || cl.help && flags.NArg() == 0 {
+		fmt.Fprint(out, options.FormatHelp())
+		return 0
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(out, "Error: No script or directory specified")
+		return 1
+	}
+
+	path := flags.Arg(0)
+	if workingDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+
+	// The first Ctrl-C stops the script at the next command, or a Wait that is running. A second one ends the
+	// program right away.
+	interrupt, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-interrupt.Done()
+		stop()
+	}()
+	cl.interrupt = interrupt
+
+	if cl.test {
+		return cl.runTests(path, out)
+	}
+	path, scriptArgs, err := FindDirectoryCommand(path, flags.Args()[1:])
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		return 1
+	}
+	return cl.runScript(path, scriptArgs, out)
+}
+
+func (cl *commandLine) boolVar(flags *flag.FlagSet, p *bool, name string, opt *Option) {
+	flags.BoolVar(p, name, opt.Default, opt.Description)
+	if opt.ShortOption != "" {
+		flags.BoolVar(p, opt.ShortOption, opt.Default, opt.Description)
+	}
+}
+
+func (cl *commandLine) runTests(path string, out io.Writer) int {
+	runner := &TestRunner{Filter: cl.filter, SkipExamples: cl.skipExamples, Strict: cl.strict, Context: cl.interrupt, Out: out}
+	results, err := runner.Run(path)
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		return 1
+	}
+	for format, file := range cl.reportFiles {
+		if *file == "" {
+			continue
+		}
+		if err := writeReport(*file, TestReports[format], results); err != nil {
+			fmt.Fprintf(out, "Error writing %s report: %v\n", format, err)
+			return 1
+		}
+	}
+	for _, result := range results {
+		if !result.Passed() {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (cl *commandLine) runScript(path string, args []string, out io.Writer) int {
+	script := NewScript(path, cl.debug, cl.output, cl.outputJSON, cl.nonInteractive)
+	script.Out = out
+	script.UserInteraction = cl.userInteraction
+	script.Strict = cl.strict
+	script.Context = cl.interrupt
+	input, err := ParseInputArgs(args)
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		return 1
+	}
+	script.Input = input
+
+	if cl.help {
+		helpText, err := script.GetScriptHelp()
+		if err != nil {
+			fmt.Fprintf(out, "Error getting help: %v\n", err)
+			return 1
+		}
+		fmt.Fprint(out, helpText)
+		return 0
+	}
+
+	if cl.emitSchema {
+		schema, err := script.EmitSchema()
+		if err != nil {
+			fmt.Fprintf(out, "Error emitting schema: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(out, schema)
+		return 0
+	}
+
+	if err := script.Execute(); err != nil {
+		if cl.debug {
+			fmt.Fprintf(out, "Error: %+v\n", err)
+		} else {
+			fmt.Fprintf(out, "Error: %v\n", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+func writeReport(file string, report TestReport, results []*TestResult) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := report(f, results); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// CliCommand runs the cli command from a script, as if it was typed on the command line. The command line is split
+// into words like a shell does, and the first word is the program name. The output is printed to the console of the
+// script, and returned. An error does not stop the script, so its message can be checked like other output; the
+// returned output ends with the exit code then. Prompts are answered the way the script answers them, so prerecorded
+// answers apply. The command runs in the temp dir, or in the directory given by cd, which is relative to the current
+// directory.
+func CliCommand(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+	line, _ := data.(string)
+	dir := ctx.TempDir()
+	if m, ok := data.(map[string]interface{}); ok {
+		line = fmt.Sprintf("%v", m["command"])
+		if cd, ok := m["cd"].(string); ok {
+			dir = cd
+		}
+	}
+
+	args, err := splitCommandLine(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 0 {
+		args = args[1:]
+	}
+
+	var output strings.Builder
+	if code := runCommandLine(args, dir, io.MultiWriter(&output, ctx.Console()), ctx.UserInteraction()); code != 0 {
+		fmt.Fprintf(&output, "Exit code: %d\n", code)
+	}
+	return output.String(), nil
+}
+
+// splitCommandLine splits a command line into words like a shell does. Text in single quotes is taken as it is, and
+// in double quotes a backslash escapes '"', '\\', '$' and '`'. Outside quotes, a backslash escapes any character.
+func splitCommandLine(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("missing closing quote in: %s", line)
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			inWord, i = true, end
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("missing closing quote in: %s", line)
+			}
+			inWord = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
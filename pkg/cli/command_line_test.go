@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"instacli/pkg/cli/commands"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := map[string][]string{
+		`cli -q basic`:                      {"cli", "-q", "basic"},
+		`  cli   "my script.cli"  `:         {"cli", "my script.cli"},
+		`cli --name 'Alice Bob'`:            {"cli", "--name", "Alice Bob"},
+		`cli --name "Say \"hi\" \$x"`:       {"cli", "--name", `Say "hi" $x`},
+		`cli --name 'It\'s`:                 {"cli", "--name", `It\s`},
+		`cli --name "open`:                  nil,
+		`cli my\ script.cli ''`:             {"cli", "my script.cli", ""},
+		`cli --name "C:\dir" --x="a b"c'd'`: {"cli", "--name", `C:\dir`, "--x=a bcd"},
+	}
+	for line, expected := range tests {
+		actual, err := splitCommandLine(line)
+		if expected == nil {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q", line, actual)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s:\n  Expected: %q\n  Actual:   %q %v", line, expected, actual, err)
+		}
+	}
+}
+
+func TestCliCommandExitCode(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"my script.cli": "Print: Hello\n"})
+
+	var console strings.Builder
+	ctx := commands.NewExecutionContext()
+	ctx.SetConsole(commands.NewConsole(&console))
+	ctx.SetTempDir(dir)
+
+	output, err := CliCommand(ctx, `cli "my script.cli"`)
+	if err != nil || output != "Hello\n" {
+		t.Errorf("Expected the script to run, got: %q %v", output, err)
+	}
+
+	output, err = CliCommand(ctx, "cli missing.cli")
+	if err != nil || !strings.HasSuffix(output.(string), "Exit code: 1\n") {
+		t.Errorf("Expected the exit code in the output, got: %q %v", output, err)
+	}
+	if strings.Contains(console.String(), "Exit code") {
+		t.Errorf("Expected the exit code not to be printed, got: %q", console.String())
+	}
+}
+
+func TestCliCommandWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"samples/hello.cli": "Print: Hello\n"})
+	t.Chdir(dir)
+
+	ctx := commands.NewExecutionContext()
+	ctx.SetConsole(commands.NewConsole(&strings.Builder{}))
+	ctx.SetTempDir(t.TempDir())
+
+	output, err := CliCommand(ctx, map[string]interface{}{"command": "cli hello.cli", "cd": "samples"})
+	if err != nil || output != "Hello\n" {
+		t.Errorf("Expected cd to be relative to the current directory, got: %q %v", output, err)
+	}
+}
+
+func TestHelpLeavesOutExtraOptions(t *testing.T) {
+	var out strings.Builder
+	if code := RunCommandLine([]string{"--help"}, "", &out); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(out.String(), "  --help, -h          Print help") {
+		t.Errorf("Expected the options to line up, got:\n%s", out.String())
+	}
+	for _, name := range []string{"--test", "--strict", "--emit-schema"} {
+		if strings.Contains(out.String(), name) {
+			t.Errorf("Expected %s not to be in the help, got:\n%s", name, out.String())
+		}
+	}
+}
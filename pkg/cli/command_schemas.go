@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"errors"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/jsonschema"
+	"instacli/pkg/spec"
+)
+
+// validateArguments checks the data of a command against the command's schema in the spec, or with its own
+// validation if it has one. Commands without either accept any data.
+func validateArguments(def *commands.Definition, data interface{}, line int) error {
+	if def.Validate != nil {
+		problems, err := def.Validate(data)
+		if err != nil || len(problems) == 0 {
+			return err
+		}
+		return &commands.CommandFormatError{Command: def.Name, Line: line, Problems: problems}
+	}
+	if def.Namespace == "" {
+		return nil
+	}
+	schema, err := spec.GetCommandSchema(def.Namespace, def.SchemaName())
+	if err != nil || schema == nil {
+		return err
+	}
+
+	err = schema.Validate(data)
+	var validationErr *jsonschema.ValidationError
+	if errors.As(err, &validationErr) {
+		formatErr := &commands.CommandFormatError{Command: def.Name, Line: line}
+		for _, e := range validationErr.Errors {
+			formatErr.Problems = append(formatErr.Problems, e.String())
+		}
+		return formatErr
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Console is where commands print their output. It writes to the underlying writer, usually standard output,
+// and also records the output while capturing, so tests can check what was printed.
+type Console struct {
+	out     io.Writer
+	capture *bytes.Buffer
+}
+
+// NewConsole creates a console that writes to the given writer
+func NewConsole(out io.Writer) *Console {
+	return &Console{out: out}
+}
+
+func (c *Console) Write(p []byte) (int, error) {
+	if c.capture != nil {
+		c.capture.Write(p)
+	}
+	return c.out.Write(p)
+}
+
+// StartCapture starts recording the output, discarding anything that was recorded before
+func (c *Console) StartCapture() {
+	c.capture = &bytes.Buffer{}
+}
+
+// Captured returns the output since capturing started. It returns false if capturing was not started.
+func (c *Console) Captured() (string, bool) {
+	if c.capture == nil {
+		return "", false
+	}
+	return c.capture.String(), true
+}
+
+// Color tells if output can be colored: the console writes to a terminal and NO_COLOR is not set
+func (c *Console) Color() bool {
+	f, ok := c.out.(*os.File)
+	if !ok || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -1,14 +1,107 @@
 package commands
 
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"instacli/pkg/cli/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TempDirVariable is the variable with the directory for temporary files of the script
+const TempDirVariable = "SCRIPT_TEMP_DIR"
+
 // ExecutionContext holds variables for script execution, especially the output variable.
+//
+// Variables belong to the script. A block can declare variables of its own with PushScope, like the loop variable of
+// For each. They hide script variables with the same name and are gone when the block ends. Assigning a variable
+// that is not declared in a block sets the script variable, which stays after the block.
 type ExecutionContext struct {
 	vars map[string]interface{}
+	// scope holds the variables declared by the innermost block that is running, or nil outside blocks
+	scope     *scope
+	strict    bool
+	types     *types.Registry
+	commands  *Registry
+	scriptDir string
+	// tempDir is where temporary files of the script are created
+	tempDir string
+	// nonInteractive tells that the user can not be asked for input
+	nonInteractive bool
+	// console is where commands print their output
+	console *Console
+	// userInteraction asks the user for input
+	userInteraction UserInteraction
+	// node is the YAML source of the data of the command that is running
+	node *yaml.Node
+	// scriptFile is the script that runs in this context, if it was read from a file
+	scriptFile string
+	// parent is the context of the script that called this one, or nil
+	parent *ExecutionContext
+	// callInput is the input that the parent gave when calling the script
+	callInput interface{}
+	// context stops the script when it is done, like on Ctrl-C or when a test times out
+	context context.Context
+}
+
+// scope holds the variables that a block declares
+type scope struct {
+	vars   map[string]interface{}
+	parent *scope
 }
 
 func NewExecutionContext() *ExecutionContext {
 	return &ExecutionContext{
-		vars: make(map[string]interface{}),
+		vars:     make(map[string]interface{}),
+		types:    types.NewRegistry(),
+		commands: NewRegistry(),
+		console:  NewConsole(os.Stdout),
+	}
+}
+
+// NewChild creates the context for a script that is called with the given input by the script running in this
+// context. The child has variables of its own, and shares the console, user interaction, commands and temp dir.
+func (ctx *ExecutionContext) NewChild(scriptFile string, input interface{}) *ExecutionContext {
+	child := &ExecutionContext{
+		vars:            make(map[string]interface{}),
+		types:           types.NewRegistry(),
+		commands:        ctx.commands,
+		scriptDir:       filepath.Dir(scriptFile),
+		nonInteractive:  ctx.nonInteractive,
+		strict:          ctx.strict,
+		console:         ctx.console,
+		userInteraction: ctx.userInteraction,
+		scriptFile:      scriptFile,
+		parent:          ctx,
+		callInput:       input,
+		context:         ctx.context,
 	}
+	if ctx.tempDir != "" {
+		child.SetTempDir(ctx.tempDir)
+	}
+	return child
+}
+
+// Parent returns the context of the script that called this one, or nil for the script that was started first
+func (ctx *ExecutionContext) Parent() *ExecutionContext {
+	return ctx.parent
+}
+
+// CallInput returns the input that the script was called with by its parent
+func (ctx *ExecutionContext) CallInput() interface{} {
+	return ctx.callInput
+}
+
+// ScriptFile returns the script that runs in this context, or an empty string if it was not read from a file
+func (ctx *ExecutionContext) ScriptFile() string {
+	return ctx.scriptFile
+}
+
+// SetScriptFile sets the script that runs in this context
+func (ctx *ExecutionContext) SetScriptFile(file string) {
+	ctx.scriptFile = file
 }
 
 func (ctx *ExecutionContext) SetOutput(value interface{}) {
@@ -19,14 +112,161 @@ func (ctx *ExecutionContext) GetOutput() interface{} {
 	return ctx.vars["output"]
 }
 
+// SetVar sets a variable. It is the variable declared by a block if there is one with the name, and the script
+// variable otherwise.
 func (ctx *ExecutionContext) SetVar(name string, value interface{}) {
+	for sc := ctx.scope; sc != nil; sc = sc.parent {
+		if _, ok := sc.vars[name]; ok {
+			sc.vars[name] = value
+			return
+		}
+	}
 	ctx.vars[name] = value
 }
 
 func (ctx *ExecutionContext) GetVar(name string) interface{} {
+	for sc := ctx.scope; sc != nil; sc = sc.parent {
+		if value, ok := sc.vars[name]; ok {
+			return value
+		}
+	}
 	return ctx.vars[name]
 }
 
+// Vars returns the variables that are visible: the script variables and the ones declared by the blocks that are
+// running. Inside a block it is a copy.
 func (ctx *ExecutionContext) Vars() map[string]interface{} {
-	return ctx.vars
+	if ctx.scope == nil {
+		return ctx.vars
+	}
+	visible := make(map[string]interface{}, len(ctx.vars))
+	for name, value := range ctx.vars {
+		visible[name] = value
+	}
+	var scopes []*scope
+	for sc := ctx.scope; sc != nil; sc = sc.parent {
+		scopes = append(scopes, sc)
+	}
+	for i := len(scopes) - 1; i >= 0; i-- {
+		for name, value := range scopes[i].vars {
+			visible[name] = value
+		}
+	}
+	return visible
+}
+
+// PushScope starts a block that declares the given variables
+func (ctx *ExecutionContext) PushScope(vars map[string]interface{}) {
+	ctx.scope = &scope{vars: vars, parent: ctx.scope}
+}
+
+// PopScope ends the innermost block. The variables it declared are gone.
+func (ctx *ExecutionContext) PopScope() {
+	if ctx.scope != nil {
+		ctx.scope = ctx.scope.parent
+	}
+}
+
+// Strict tells if referring to an unknown variable is an error. Otherwise the reference is kept as text.
+func (ctx *ExecutionContext) Strict() bool {
+	return ctx.strict
+}
+
+// SetStrict sets if referring to an unknown variable is an error
+func (ctx *ExecutionContext) SetStrict(strict bool) {
+	ctx.strict = strict
+}
+
+// Types returns the registry with the types that are known to the script
+func (ctx *ExecutionContext) Types() *types.Registry {
+	return ctx.types
+}
+
+// SetTypes sets the registry with the types that are known to the script
+func (ctx *ExecutionContext) SetTypes(registry *types.Registry) {
+	ctx.types = registry
+}
+
+// ScriptDir returns the directory of the script, used to resolve files relative to the script
+func (ctx *ExecutionContext) ScriptDir() string {
+	return ctx.scriptDir
+}
+
+// SetScriptDir sets the directory of the script
+func (ctx *ExecutionContext) SetScriptDir(dir string) {
+	ctx.scriptDir = dir
+}
+
+// TempDir returns the directory where temporary files of the script are created
+func (ctx *ExecutionContext) TempDir() string {
+	return ctx.tempDir
+}
+
+// SetTempDir sets the directory where temporary files of the script are created, and the variable that refers to it
+func (ctx *ExecutionContext) SetTempDir(dir string) {
+	ctx.tempDir = dir
+	ctx.vars[TempDirVariable] = dir
+}
+
+// Commands returns the registry with the commands that are available to the script
+func (ctx *ExecutionContext) Commands() *Registry {
+	return ctx.commands
+}
+
+// SetCommands sets the registry with the commands that are available to the script
+func (ctx *ExecutionContext) SetCommands(registry *Registry) {
+	ctx.commands = registry
+}
+
+// NonInteractive tells if the user can not be asked for input
+func (ctx *ExecutionContext) NonInteractive() bool {
+	return ctx.nonInteractive
+}
+
+// SetNonInteractive sets whether the user can be asked for input
+func (ctx *ExecutionContext) SetNonInteractive(nonInteractive bool) {
+	ctx.nonInteractive = nonInteractive
+}
+
+// CommandNode returns the YAML source of the data of the command that is running, or nil if it is not known
+func (ctx *ExecutionContext) CommandNode() *yaml.Node {
+	return ctx.node
+}
+
+// SetCommandNode sets the YAML source of the data of the command that is running
+func (ctx *ExecutionContext) SetCommandNode(node *yaml.Node) {
+	ctx.node = node
+}
+
+// UserInteraction returns the way the user is asked for input
+func (ctx *ExecutionContext) UserInteraction() UserInteraction {
+	return ctx.userInteraction
+}
+
+// SetUserInteraction sets the way the user is asked for input
+func (ctx *ExecutionContext) SetUserInteraction(ui UserInteraction) {
+	ctx.userInteraction = ui
+}
+
+// Context tells commands that wait, like Wait, when to stop. It is never done if no context was set.
+func (ctx *ExecutionContext) Context() context.Context {
+	if ctx.context == nil {
+		return context.Background()
+	}
+	return ctx.context
+}
+
+// SetContext sets the context that stops the script
+func (ctx *ExecutionContext) SetContext(c context.Context) {
+	ctx.context = c
+}
+
+// Console returns where commands print their output
+func (ctx *ExecutionContext) Console() *Console {
+	return ctx.console
+}
+
+// SetConsole sets where commands print their output
+func (ctx *ExecutionContext) SetConsole(console *Console) {
+	ctx.console = console
 }
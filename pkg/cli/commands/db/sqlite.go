@@ -0,0 +1,97 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"instacli/pkg/values"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Open opens the SQLite database stored in the given file, creating it if it does not exist.
+func Open(file string) (*sql.DB, error) {
+	conn, err := sql.Open("sqlite3", file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database %s: %w", file, err)
+	}
+	return conn, nil
+}
+
+// doUpdate executes a statement that does not return rows.
+func doUpdate(conn *sql.DB, statement string, args ...interface{}) error {
+	if _, err := conn.Exec(statement, args...); err != nil {
+		return fmt.Errorf("error executing '%s': %w", statement, err)
+	}
+	return nil
+}
+
+// doJSONQuery executes a query where every column contains JSON and returns one object per row.
+// A single column named 'json' is returned as the row itself.
+func doJSONQuery(conn *sql.DB, query string, args ...interface{}) ([]interface{}, error) {
+	rows, err := conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing '%s': %w", query, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []interface{}{}
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		if len(columns) == 1 && columns[0] == jsonColumn {
+			row, err := parseJSON(values[0])
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, row)
+			continue
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			value, err := parseJSON(values[i])
+			if err != nil {
+				return nil, err
+			}
+			row[column] = value
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
+
+// parseJSON decodes a JSON column value into the same values as YAML data.
+func parseJSON(value sql.NullString) (interface{}, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	result, err := values.FromJSON([]byte(value.String))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JSON from database: %w", err)
+	}
+	return result, nil
+}
+
+// quoteIdentifier quotes a table or column name for use in SQL.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteLiteral quotes a text for use as a string literal in SQL.
+func quoteLiteral(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
@@ -0,0 +1,377 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/types"
+)
+
+const (
+	jsonColumn   = "json"
+	defaultTable = "json_data"
+	// idType is the schema type of a field that refers to the id column of the table
+	idType = "id"
+	// dataType is the schema type of a field that holds any JSON data, an object or a list
+	dataType = "object"
+	// queryAll is the short form of a query for all documents
+	queryAll = "all"
+)
+
+// StoreCommand represents the "Store" command: a document store on top of a SQLite table.
+// Every document is kept as JSON in a single column and queried with JSON paths like $.name. Fields of the schema
+// can be named without '$.' in where clauses.
+type StoreCommand struct {
+	File  string
+	Table string
+	// Schema has the type of each field: 'id', 'object' or the name of a type
+	Schema map[string]string
+	Insert []interface{}
+	Update *UpdateData
+	Delete *DeleteData
+	Query  *QueryData
+}
+
+// QueryData selects documents or fields of documents that match the where clause.
+type QueryData struct {
+	Select []string
+	Where  string
+}
+
+// UpdateData sets fields on all documents that match the where clause.
+type UpdateData struct {
+	Where string
+	Set   map[string]interface{}
+}
+
+// DeleteData removes all documents that match the where clause.
+type DeleteData struct {
+	Where string
+}
+
+// NewStoreCommand creates a new Store command. The database file is given as 'file' or 'db'. The query is an object
+// with 'select' and 'where', or 'all'.
+func NewStoreCommand(data map[string]interface{}) (*StoreCommand, error) {
+	cmd := &StoreCommand{Table: defaultTable}
+
+	for _, name := range []string{"file", "db"} {
+		if file, ok := data[name].(string); ok && file != "" {
+			cmd.File = file
+		}
+	}
+	if cmd.File == "" {
+		return nil, fmt.Errorf("missing required parameter: file")
+	}
+	if table, ok := data["table"]; ok {
+		tableName, ok := table.(string)
+		if !ok || tableName == "" {
+			return nil, fmt.Errorf("'table' must be a text")
+		}
+		cmd.Table = tableName
+	}
+	if schema, ok := data["schema"]; ok {
+		fields, ok := schema.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'schema' must be an object with a type for each field")
+		}
+		cmd.Schema = make(map[string]string, len(fields))
+		for field, fieldType := range fields {
+			typeName, ok := fieldType.(string)
+			if !ok || typeName == "" {
+				return nil, fmt.Errorf("the type of field '%s' in schema must be a type name, got: %v", field, fieldType)
+			}
+			cmd.Schema[field] = typeName
+		}
+	}
+	if insert, ok := data["insert"]; ok {
+		switch v := insert.(type) {
+		case []interface{}:
+			cmd.Insert = v
+		case map[string]interface{}:
+			cmd.Insert = []interface{}{v}
+		default:
+			return nil, fmt.Errorf("'insert' must be an object or a list of objects")
+		}
+	}
+	if update, ok := data["update"]; ok {
+		m, ok := update.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'update' must be an object with 'where' and 'set'")
+		}
+		cmd.Update = &UpdateData{}
+		cmd.Update.Where, _ = m["where"].(string)
+		cmd.Update.Set, ok = m["set"].(map[string]interface{})
+		if !ok || len(cmd.Update.Set) == 0 {
+			return nil, fmt.Errorf("'update' needs the fields to change in 'set'")
+		}
+	}
+	if del, ok := data["delete"]; ok {
+		m, ok := del.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'delete' must be an object with 'where'")
+		}
+		where, ok := m["where"].(string)
+		if !ok || where == "" {
+			return nil, fmt.Errorf("'delete' needs a 'where' clause")
+		}
+		cmd.Delete = &DeleteData{Where: where}
+	}
+	if query, ok := data["query"]; ok {
+		if query == queryAll {
+			query = map[string]interface{}{}
+		}
+		m, ok := query.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'query' must be '%s' or an object with 'select' and 'where', got: %v", queryAll, query)
+		}
+		cmd.Query = &QueryData{}
+		cmd.Query.Where, _ = m["where"].(string)
+		if sel, ok := m["select"]; ok {
+			fields, ok := sel.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("'select' must be a list of fields")
+			}
+			for _, field := range fields {
+				cmd.Query.Select = append(cmd.Query.Select, fmt.Sprintf("%v", field))
+			}
+		}
+	}
+
+	return cmd, nil
+}
+
+// Execute runs the Store command. The result of the query, if any, becomes the output.
+func (c *StoreCommand) Execute(ctx *commands.ExecutionContext) error {
+	for _, doc := range c.Insert {
+		if err := c.validate(ctx.Types(), doc); err != nil {
+			return err
+		}
+	}
+	if c.Update != nil {
+		if err := c.validate(ctx.Types(), c.Update.Set); err != nil {
+			return err
+		}
+	}
+
+	conn, err := Open(c.File)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	table := quoteIdentifier(c.Table)
+	if err := doUpdate(conn, fmt.Sprintf("create table if not exists %s (id integer primary key, %s text)", table, jsonColumn)); err != nil {
+		return err
+	}
+
+	for _, doc := range c.Insert {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("error converting document to JSON: %w", err)
+		}
+		if err := doUpdate(conn, fmt.Sprintf("insert into %s (%s) values (?)", table, jsonColumn), string(data)); err != nil {
+			return err
+		}
+	}
+
+	if c.Update != nil {
+		statement, args, err := c.Update.toSQL(table, c.fields())
+		if err != nil {
+			return err
+		}
+		if err := doUpdate(conn, statement, args...); err != nil {
+			return err
+		}
+	}
+
+	if c.Delete != nil {
+		statement := fmt.Sprintf("delete from %s where %s", table, expandJSONPaths(c.Delete.Where, c.fields()))
+		if err := doUpdate(conn, statement); err != nil {
+			return err
+		}
+	}
+
+	if c.Query == nil {
+		return nil
+	}
+	result, err := doJSONQuery(conn, c.Query.toSQL(table, c.fields()))
+	if err != nil {
+		return err
+	}
+	ctx.SetOutput(result)
+	return nil
+}
+
+// fields returns the fields of the schema that are looked up in the documents, so not the ones of type 'id'
+func (c *StoreCommand) fields() []string {
+	var fields []string
+	for field, typeName := range c.Schema {
+		if typeName != idType {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (q *QueryData) toSQL(table string, fields []string) string {
+	selection := jsonColumn
+	if len(q.Select) > 0 {
+		columns := make([]string, len(q.Select))
+		for i, field := range q.Select {
+			columns[i] = fmt.Sprintf("%s -> %s as %s", jsonColumn, quoteLiteral(asJSONPath(field)), quoteIdentifier(field))
+		}
+		selection = strings.Join(columns, ", ")
+	}
+	return fmt.Sprintf("select %s from %s%s", selection, table, whereClause(q.Where, fields))
+}
+
+func (u *UpdateData) toSQL(table string, fields []string) (string, []interface{}, error) {
+	names := make([]string, 0, len(u.Set))
+	for field := range u.Set {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	var assignments []string
+	var args []interface{}
+	for _, field := range names {
+		value, err := json.Marshal(u.Set[field])
+		if err != nil {
+			return "", nil, fmt.Errorf("error converting '%s' to JSON: %w", field, err)
+		}
+		assignments = append(assignments, quoteLiteral(asJSONPath(field))+", json(?)")
+		args = append(args, string(value))
+	}
+	statement := fmt.Sprintf("update %s set %s = json_set(%s, %s)%s",
+		table, jsonColumn, jsonColumn, strings.Join(assignments, ", "), whereClause(u.Where, fields))
+	return statement, args, nil
+}
+
+func whereClause(where string, fields []string) string {
+	if strings.TrimSpace(where) == "" {
+		return ""
+	}
+	return " where " + expandJSONPaths(where, fields)
+}
+
+// asJSONPath turns a field name like 'address.city' into the JSON path '$.address.city'.
+func asJSONPath(field string) string {
+	if strings.HasPrefix(field, "$") {
+		return field
+	}
+	return "$." + field
+}
+
+// jsonPathRegex matches JSON paths like $.name or $.items[0].name, and words that may be the name of a field
+var jsonPathRegex = regexp.MustCompile(`\$(?:\.\w+|\[\d+\])+|[A-Za-z_]\w*`)
+
+// expandJSONPaths replaces JSON paths in a predicate with lookups in the JSON column,
+// so "$.language = 'Spanish'" becomes "json_extract(json, '$.language') = 'Spanish'".
+// The given fields are expanded too when they are named without '$.', so "age < 18" is the same as "$.age < 18".
+// Paths inside string literals are left alone.
+func expandJSONPaths(predicate string, fields []string) string {
+	var result strings.Builder
+	inLiteral := false
+	start := 0
+	for i := 0; i < len(predicate); i++ {
+		if predicate[i] != '\'' {
+			continue
+		}
+		if !inLiteral {
+			result.WriteString(expandJSONPathsInExpression(predicate[start:i], fields))
+			start = i
+		} else {
+			result.WriteString(predicate[start : i+1])
+			start = i + 1
+		}
+		inLiteral = !inLiteral
+	}
+	if inLiteral {
+		result.WriteString(predicate[start:])
+	} else {
+		result.WriteString(expandJSONPathsInExpression(predicate[start:], fields))
+	}
+	return result.String()
+}
+
+func expandJSONPathsInExpression(expression string, fields []string) string {
+	return jsonPathRegex.ReplaceAllStringFunc(expression, func(path string) string {
+		if !strings.HasPrefix(path, "$") {
+			if !isField(path, fields) {
+				return path
+			}
+			path = asJSONPath(path)
+		}
+		return fmt.Sprintf("json_extract(%s, %s)", jsonColumn, quoteLiteral(path))
+	})
+}
+
+func isField(name string, fields []string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks a document against the schema, if one was given. The fields are checked against their types in
+// the registry, and fields that the schema does not have are not allowed.
+func (c *StoreCommand) validate(registry *types.Registry, doc interface{}) error {
+	if c.Schema == nil {
+		return nil
+	}
+	fields, ok := doc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("Store: document must be an object, got: %v", doc)
+	}
+
+	var violations []string
+	for _, name := range sortedNames(fields) {
+		if _, ok := c.Schema[name]; !ok {
+			violations = append(violations, fmt.Sprintf("unknown field '%s'", name))
+		}
+	}
+	if err := registry.Check(doc, c.documentType()); err != nil {
+		var invalid *types.ValidationError
+		if !errors.As(err, &invalid) {
+			return fmt.Errorf("Store: invalid schema for table %s: %w", c.Table, err)
+		}
+		for _, v := range invalid.Violations {
+			violations = append(violations, v.String())
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("Store: invalid document for table %s:\n  %s", c.Table, strings.Join(violations, "\n  "))
+	}
+	return nil
+}
+
+// documentType is the type of the documents in the table: an object with the fields of the schema. Fields are
+// optional. Fields of type 'id' and 'object' accept any value.
+func (c *StoreCommand) documentType() *types.Type {
+	t := &types.Type{Base: types.Object, Properties: types.ObjectProperties{}}
+	for name, typeName := range c.Schema {
+		prop := &types.Property{Name: name, Optional: true}
+		if typeName != idType && typeName != dataType {
+			prop.Type = types.Reference(typeName)
+		}
+		t.Properties = append(t.Properties, prop)
+	}
+	sort.Slice(t.Properties, func(i, j int) bool { return t.Properties[i].Name < t.Properties[j].Name })
+	return t
+}
+
+func sortedNames(fields map[string]interface{}) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
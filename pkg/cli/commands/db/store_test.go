@@ -0,0 +1,230 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"instacli/pkg/cli/commands"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpandJSONPaths(t *testing.T) {
+	tests := map[string]string{
+		"select $.a where $.b":      "select json_extract(json, '$.a') where json_extract(json, '$.b')",
+		"$.language = 'Spanish'":    "json_extract(json, '$.language') = 'Spanish'",
+		"$.items[0].name = '$.x'":   "json_extract(json, '$.items[0].name') = '$.x'",
+		"$.name = 'it''s $.here'":   "json_extract(json, '$.name') = 'it''s $.here'",
+		"length($.name) > 3":        "length(json_extract(json, '$.name')) > 3",
+		"$.age < 18 and $.age > 10": "json_extract(json, '$.age') < 18 and json_extract(json, '$.age') > 10",
+	}
+	for input, expected := range tests {
+		if actual := expandJSONPaths(input, nil); actual != expected {
+			t.Errorf("expandJSONPaths(%q)\n  Expected: %s\n  Actual:   %s", input, expected, actual)
+		}
+	}
+
+	// Fields of the schema can be named without '$.'
+	input := "age < 18 and name != 'age' and ages > 1"
+	expected := "json_extract(json, '$.age') < 18 and json_extract(json, '$.name') != 'age' and ages > 1"
+	if actual := expandJSONPaths(input, []string{"age", "name"}); actual != expected {
+		t.Errorf("expandJSONPaths(%q)\n  Expected: %s\n  Actual:   %s", input, expected, actual)
+	}
+}
+
+// runStore runs a Store command given in YAML against a database in a temporary directory
+func runStore(t *testing.T, file string, source string) (interface{}, error) {
+	t.Helper()
+	var data map[string]interface{}
+	if err := yaml.Unmarshal([]byte(source), &data); err != nil {
+		t.Fatalf("invalid test data: %v", err)
+	}
+	data["file"] = file
+
+	cmd, err := NewStoreCommand(data)
+	if err != nil {
+		return nil, err
+	}
+	ctx := commands.NewExecutionContext()
+	if err := cmd.Execute(ctx); err != nil {
+		return nil, err
+	}
+	return ctx.GetOutput(), nil
+}
+
+func assertYAML(t *testing.T, actual interface{}, expectedYAML string) {
+	t.Helper()
+	var expected interface{}
+	if err := yaml.Unmarshal([]byte(expectedYAML), &expected); err != nil {
+		t.Fatalf("invalid expected data: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		actualYAML, _ := yaml.Marshal(actual)
+		t.Errorf("Not equal:\n  Expected: %s\n  Actual:   %s", expectedYAML, actualYAML)
+	}
+}
+
+func TestStoreSelectWithWhereClause(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sample.db")
+	output, err := runStore(t, file, `
+table: json_data
+insert:
+  - greeting: Hello
+    language: English
+  - greeting: Hola
+    language: Spanish
+query:
+  where: $.language = 'Spanish'
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertYAML(t, output, `
+- greeting: Hola
+  language: Spanish
+`)
+}
+
+func TestStoreSelectedFields(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sample.db")
+	output, err := runStore(t, file, `
+table: users
+insert:
+  - name: Alice
+    age: 16
+    address:
+      city: Amsterdam
+  - name: Bob
+    age: 17
+  - name: Charlie
+    age: 18
+query:
+  select:
+    - name
+    - address.city
+  where: $.age < 18
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertYAML(t, output, `
+- name: Alice
+  address.city: Amsterdam
+- name: Bob
+  address.city: null
+`)
+}
+
+func TestStoreUpdateAndDelete(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sample.db")
+	_, err := runStore(t, file, `
+table: users
+insert:
+  - name: Alice
+    age: 16
+  - name: Bob
+    age: 17
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runStore(t, file, `
+table: users
+update:
+  where: $.name = 'Alice'
+  set:
+    age: 18
+    roles: [admin]
+delete:
+  where: $.name = 'Bob'
+query:
+  where: $.age > 0
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertYAML(t, output, `
+- name: Alice
+  age: 18
+  roles: [admin]
+`)
+}
+
+func TestStoreWithSchema(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sample.db")
+	schema := `
+table: users
+schema:
+  id: id
+  name: string
+  age: number
+`
+	if _, err := runStore(t, file, schema+`
+insert:
+  - name: Alice
+    age: 16
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := runStore(t, file, schema+`
+insert:
+  - name: Bob
+    age: seventeen
+    email: bob@example.com
+`)
+	if err == nil {
+		t.Fatal("expected schema violation")
+	}
+	for _, expected := range []string{"unknown field 'email'", "$.age: Data should be number but is string"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q, got: %v", expected, err)
+		}
+	}
+}
+
+// The short forms of the scratchpad example 'Store data with schema'
+func TestStoreShortForms(t *testing.T) {
+	var data map[string]interface{}
+	source := `
+table: users
+schema:
+  id: id
+  name: string
+  age: number
+insert:
+  - name: Alice
+    age: 16
+  - name: Bob
+    age: 18
+query:
+  select: [name]
+  where: age < 18
+`
+	if err := yaml.Unmarshal([]byte(source), &data); err != nil {
+		t.Fatal(err)
+	}
+	data["db"] = filepath.Join(t.TempDir(), "sample.db")
+	cmd, err := NewStoreCommand(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := commands.NewExecutionContext()
+	if err := cmd.Execute(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertYAML(t, ctx.GetOutput(), "[{name: Alice}]")
+
+	output, err := runStore(t, data["db"].(string), "table: users\nquery: all\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertYAML(t, output, "[{name: Alice, age: 16}, {name: Bob, age: 18}]")
+
+	if _, err := runStore(t, data["db"].(string), "query: everything\n"); err == nil {
+		t.Error("expected a query that is not 'all' or an object to be an error")
+	}
+}
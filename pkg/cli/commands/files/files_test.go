@@ -0,0 +1,94 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"instacli/pkg/cli/commands"
+)
+
+func TestWriteAndReadFile(t *testing.T) {
+	dir := t.TempDir()
+	content := map[string]interface{}{"greeting": "Hello", "count": 2}
+	tests := []struct {
+		file     string
+		content  interface{}
+		expected interface{}
+	}{
+		{"out/data.yaml", content, content},
+		{"out/data.json", content, content},
+		{"out/data.txt", "Hello, World!", "Hello, World!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			file := filepath.Join(dir, tt.file)
+			if err := (&WriteFileCommand{File: file, Content: tt.content}).Execute(); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := NewReadFileCommand(file).Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Not equal:\n  Expected: %v\n  Actual:   %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReadMultipleDocuments(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "documents.yaml")
+	if err := os.WriteFile(file, []byte("a: 1\n---\nb: ${unknown}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := NewReadFileCommand(file).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{map[string]interface{}{"a": 1}, map[string]interface{}{"b": "${unknown}"}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Not equal:\n  Expected: %v\n  Actual:   %v", expected, actual)
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	ctx := commands.NewExecutionContext()
+	ctx.SetScriptDir("scripts")
+	tests := []struct {
+		data     interface{}
+		expected string
+	}{
+		{"data/file.yaml", "data/file.yaml"},
+		{map[string]interface{}{"file": "data/file.yaml"}, "data/file.yaml"},
+		{map[string]interface{}{"resource": "file.yaml"}, filepath.Join("scripts", "file.yaml")},
+	}
+	for _, tt := range tests {
+		actual, err := ResolvePath(ctx, "Read file", tt.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tt.expected {
+			t.Errorf("Not equal:\n  Expected: %s\n  Actual:   %s", tt.expected, actual)
+		}
+	}
+	if _, err := ResolvePath(ctx, "Read file", map[string]interface{}{}); err == nil {
+		t.Error("Expected an error without file or resource")
+	}
+}
+
+func TestTempFileWithTypes(t *testing.T) {
+	dir := t.TempDir()
+	ctx := commands.NewExecutionContext()
+	ctx.SetScriptDir(dir)
+	ctx.SetTempDir(dir)
+
+	content := map[string]interface{}{"FullName": map[string]interface{}{"base": "object"}}
+	if _, err := (&TempFileCommand{Filename: "types.yaml", Content: content}).Execute(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Types().Get("FullName") == nil {
+		t.Error("Expected the types in the temp file to be known")
+	}
+}
@@ -0,0 +1,44 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"instacli/pkg/cli/commands"
+)
+
+// ResolvePath returns the file that command data refers to. The data is a path relative to the working directory,
+// or an object with either 'file', a path relative to the working directory, or 'resource', a path relative to
+// the directory of the script.
+func ResolvePath(ctx *commands.ExecutionContext, command string, data interface{}) (string, error) {
+	switch d := data.(type) {
+	case string:
+		return d, nil
+	case map[string]interface{}:
+		if file, ok := d["file"]; ok {
+			return fmt.Sprintf("%v", file), nil
+		}
+		if resource, ok := d["resource"]; ok {
+			resourcePath := fmt.Sprintf("%v", resource)
+			if filepath.IsAbs(resourcePath) {
+				return resourcePath, nil
+			}
+			return filepath.Join(ctx.ScriptDir(), resourcePath), nil
+		}
+		return "", fmt.Errorf("%s: expected either 'file' or 'resource' property", command)
+	default:
+		return "", fmt.Errorf("%s: expected a file name, got: %v", command, data)
+	}
+}
+
+// existingFile returns an error if the file does not exist
+func existingFile(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("File not found: %s", file)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"instacli/pkg/values"
+)
+
+// ReadFileCommand represents the "Read file" command
+type ReadFileCommand struct {
+	File string
+}
+
+// NewReadFileCommand creates a new Read file command for a file that was resolved with ResolvePath
+func NewReadFileCommand(file string) *ReadFileCommand {
+	return &ReadFileCommand{File: file}
+}
+
+// Execute reads the file. YAML and JSON files, recognized by their extension, are parsed. A file with more than
+// one YAML document gives a list of the documents. Other files are returned as text.
+func (c *ReadFileCommand) Execute() (interface{}, error) {
+	if err := existingFile(c.File); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(c.File)
+	if err != nil {
+		return nil, fmt.Errorf("can not read file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(c.File)) {
+	case ".yaml", ".yml", ".json":
+		return parseDocuments(data)
+	default:
+		return string(data), nil
+	}
+}
+
+func parseDocuments(data []byte) (interface{}, error) {
+	var documents []interface{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("can not parse file: %w", err)
+		}
+		doc, err := values.FromNode(&node)
+		if err != nil {
+			return nil, fmt.Errorf("can not parse file: %w", err)
+		}
+		documents = append(documents, doc)
+	}
+
+	switch len(documents) {
+	case 0:
+		return nil, nil
+	case 1:
+		return documents[0], nil
+	default:
+		return documents, nil
+	}
+}
@@ -0,0 +1,91 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/commands/variables"
+	"instacli/pkg/cli/types"
+)
+
+// TempFileCommand represents the "Temp file" command
+type TempFileCommand struct {
+	// Filename is the name of the file in the temp dir of the script. A unique name is chosen if it is empty.
+	Filename string
+	Content  interface{}
+	// Resolve tells if variables in the content are replaced
+	Resolve bool
+}
+
+// NewTempFileCommand creates a new Temp file command. The data is either the content or an object with
+// filename, resolve and content.
+func NewTempFileCommand(data interface{}) (*TempFileCommand, error) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return &TempFileCommand{Content: data, Resolve: true}, nil
+	}
+
+	content, ok := m["content"]
+	if !ok {
+		return nil, fmt.Errorf("Temp file: missing 'content'")
+	}
+	c := &TempFileCommand{Content: content, Resolve: true}
+	if filename, ok := m["filename"]; ok {
+		c.Filename = fmt.Sprintf("%v", filename)
+	}
+	if resolve, ok := m["resolve"].(bool); ok {
+		c.Resolve = resolve
+	}
+	return c, nil
+}
+
+// Execute writes the content to a file in the temp dir of the script and returns its path. Content that is not
+// text is written like Write file does. The temp dir is removed when the script ends. A types file that is written
+// in the directory of the script adds its types to the ones the script knows.
+func (c *TempFileCommand) Execute(ctx *commands.ExecutionContext) (string, error) {
+	content := c.Content
+	if c.Resolve {
+		resolved, err := variables.ResolveVariablesRecursive(content, ctx.Vars())
+		if err != nil {
+			return "", err
+		}
+		content = resolved
+	}
+
+	file, err := c.create(ctx.TempDir())
+	if err != nil {
+		return "", fmt.Errorf("can not create temp file: %w", err)
+	}
+	text, err := formatContent(file, content)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(file, []byte(text), 0o644); err != nil {
+		return "", fmt.Errorf("can not write temp file: %w", err)
+	}
+	if file == filepath.Join(ctx.ScriptDir(), types.TypesFile) {
+		if err := ctx.Types().LoadDir(ctx.ScriptDir()); err != nil {
+			return "", err
+		}
+	}
+	return file, nil
+}
+
+func (c *TempFileCommand) create(dir string) (string, error) {
+	if c.Filename == "" {
+		f, err := os.CreateTemp(dir, "instacli-temp-file-")
+		if err != nil {
+			return "", err
+		}
+		return f.Name(), f.Close()
+	}
+
+	file := filepath.Join(dir, c.Filename)
+	if !strings.HasPrefix(file, filepath.Clean(dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("file name is outside the temp dir: %s", c.Filename)
+	}
+	return file, os.MkdirAll(filepath.Dir(file), 0o755)
+}
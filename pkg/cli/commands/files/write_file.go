@@ -0,0 +1,71 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/values"
+)
+
+// WriteFileCommand represents the "Write file" command
+type WriteFileCommand struct {
+	File    string
+	Content interface{}
+}
+
+// NewWriteFileCommand creates a new Write file command. The short form is the file name, and the output is written.
+// The long form has 'file' and optionally 'content', which defaults to the output.
+func NewWriteFileCommand(ctx *commands.ExecutionContext, data interface{}) (*WriteFileCommand, error) {
+	switch d := data.(type) {
+	case string:
+		return &WriteFileCommand{File: d, Content: ctx.GetOutput()}, nil
+	case map[string]interface{}:
+		file, ok := d["file"]
+		if !ok {
+			return nil, fmt.Errorf("Write file: missing 'file'")
+		}
+		content, ok := d["content"]
+		if !ok {
+			content = ctx.GetOutput()
+		}
+		return &WriteFileCommand{File: fmt.Sprintf("%v", file), Content: content}, nil
+	default:
+		return nil, fmt.Errorf("Write file: expected a file name, got: %v", data)
+	}
+}
+
+// Execute writes the content to the file, creating the directories it is in. Text is written as it is, other
+// content as JSON for .json files and as YAML otherwise.
+func (c *WriteFileCommand) Execute() error {
+	if c.Content == nil {
+		return fmt.Errorf("Write file requires 'content' parameter or non-null output variable.")
+	}
+
+	text, err := formatContent(c.File, c.Content)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.File), 0o755); err != nil {
+		return fmt.Errorf("can not create directory: %w", err)
+	}
+	if err := os.WriteFile(c.File, []byte(text), 0o644); err != nil {
+		return fmt.Errorf("can not write file: %w", err)
+	}
+	return nil
+}
+
+// formatContent writes content that is not text as YAML, or as JSON for a .json file.
+func formatContent(file string, content interface{}) (string, error) {
+	if text, ok := content.(string); ok {
+		return text, nil
+	}
+	if strings.ToLower(filepath.Ext(file)) == ".json" {
+		out, err := json.MarshalIndent(content, "", "  ")
+		return string(out) + "\n", err
+	}
+	return values.YAML(content)
+}
@@ -0,0 +1,17 @@
+package commands
+
+// UserInteraction asks the user for input. All commands that prompt the user go through it,
+// so input can come from a terminal or from prerecorded answers.
+type UserInteraction interface {
+	// Prompt asks the user to type an answer. The input is hidden for passwords.
+	Prompt(message string, defaultValue string, password bool) (interface{}, error)
+	// Select asks the user to select one item from a list, or one or more if multiple is set. The default is the
+	// answer if the user does not choose, given like an answer; it is nil if there is none.
+	Select(message string, choices []Choice, defaultValue interface{}, multiple bool) ([]Choice, error)
+}
+
+// Choice is an item the user can select, with the text that is shown for it
+type Choice struct {
+	Display string
+	Value   interface{}
+}
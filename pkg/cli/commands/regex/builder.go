@@ -0,0 +1,250 @@
+package regex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// keywords are the elements of a regex that do not take data, like '- any'
+var keywords = map[string]string{
+	"any":             ".",
+	"digit":           `\d`,
+	"letter":          `\pL`,
+	"word character":  `\w`,
+	"whitespace":      `\s`,
+	"start of string": "^",
+	"end of string":   "$",
+	"start of line":   `(?m:^)`,
+	"end of line":     `(?m:$)`,
+	"word boundary":   `\b`,
+}
+
+// repetitions are the elements that repeat the elements in their data
+var repetitions = map[string]string{
+	"one or more":  "+",
+	"zero or more": "*",
+	"optional":     "?",
+}
+
+// unsupported are elements that are common in other regex engines, but that Go's RE2 engine does not have
+var unsupported = map[string]string{
+	"followed by":     "lookaheads",
+	"not followed by": "lookaheads",
+	"preceded by":     "lookbehinds",
+	"not preceded by": "lookbehinds",
+	"same as":         "backreferences",
+}
+
+// Build makes a regular expression from its readable form: a list of elements that are matched one after the other.
+// An element is a keyword like 'any' or 'whitespace', or an object with one of these properties:
+//
+//	text            the text as it is
+//	not             any character that is not in the text
+//	any of          any character that is in the text
+//	group           a group of elements, named with a 'name' element
+//	one or more     the elements, repeated
+//	zero or more    the elements, repeated or left out
+//	optional        the elements, or nothing
+//	either          one of a list of alternatives
+//	pattern         a regular expression as it is
+func Build(data interface{}) (string, error) {
+	b := &builder{}
+	pattern, err := b.sequence(data)
+	if err != nil {
+		return "", err
+	}
+	if _, err := Compile(pattern); err != nil {
+		return "", err
+	}
+	return pattern, nil
+}
+
+type builder struct {
+	groupNames map[string]bool
+}
+
+// sequence builds the elements in a list, or a single element
+func (b *builder) sequence(data interface{}) (string, error) {
+	pattern, _, err := b.parts(data)
+	return pattern, err
+}
+
+// parts builds a sequence. It also tells if the sequence is a single character, class or group, that a repetition
+// can follow without putting it in a group first.
+func (b *builder) parts(data interface{}) (string, bool, error) {
+	elements, ok := data.([]interface{})
+	if !ok {
+		elements = []interface{}{data}
+	}
+	var pattern strings.Builder
+	for _, element := range elements {
+		part, err := b.element(element)
+		if err != nil {
+			return "", false, err
+		}
+		pattern.WriteString(part)
+	}
+	single := len(elements) == 1 && isSingle(pattern.String())
+	return pattern.String(), single, nil
+}
+
+func (b *builder) element(element interface{}) (string, error) {
+	switch e := element.(type) {
+	case string:
+		if pattern, ok := keywords[e]; ok {
+			return pattern, nil
+		}
+		if feature, ok := unsupported[e]; ok {
+			return "", unsupportedError(e, feature)
+		}
+		if _, ok := repetitions[e]; ok {
+			return "", fmt.Errorf("Regex: '%s' needs the elements to repeat", e)
+		}
+		return "", fmt.Errorf("Regex: unknown element '%s'", e)
+	case map[string]interface{}:
+		if len(e) != 1 {
+			return "", fmt.Errorf("Regex: an element has one property, got: %s", strings.Join(sortedKeys(e), ", "))
+		}
+		for key, value := range e {
+			return b.property(key, value)
+		}
+	}
+	return "", fmt.Errorf("Regex: unknown element %v", element)
+}
+
+func (b *builder) property(key string, value interface{}) (string, error) {
+	if repeat, ok := repetitions[key]; ok {
+		pattern, single, err := b.parts(value)
+		if err != nil {
+			return "", err
+		}
+		if pattern == "" {
+			return "", fmt.Errorf("Regex: '%s' needs the elements to repeat", key)
+		}
+		if !single {
+			pattern = "(?:" + pattern + ")"
+		}
+		return pattern + repeat, nil
+	}
+	if feature, ok := unsupported[key]; ok {
+		return "", unsupportedError(key, feature)
+	}
+
+	switch key {
+	case "text":
+		return quoteText(fmt.Sprint(value)), nil
+	case "not":
+		return "[^" + characterClass(fmt.Sprint(value)) + "]", nil
+	case "any of":
+		return "[" + characterClass(fmt.Sprint(value)) + "]", nil
+	case "pattern":
+		pattern := fmt.Sprint(value)
+		if _, err := Compile(pattern); err != nil {
+			return "", err
+		}
+		return "(?:" + pattern + ")", nil
+	case "group":
+		return b.group(value)
+	case "either":
+		alternatives, ok := value.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("Regex: 'either' needs a list of alternatives, got: %v", value)
+		}
+		parts := make([]string, 0, len(alternatives))
+		for _, alternative := range alternatives {
+			part, err := b.sequence(alternative)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return "(?:" + strings.Join(parts, "|") + ")", nil
+	case "name":
+		return "", fmt.Errorf("Regex: 'name' can only be used in a group")
+	}
+	return "", fmt.Errorf("Regex: unknown element '%s'", key)
+}
+
+// group builds a capturing group. A 'name' element in it names the group.
+func (b *builder) group(value interface{}) (string, error) {
+	elements, ok := value.([]interface{})
+	if !ok {
+		elements = []interface{}{value}
+	}
+	name := ""
+	var rest []interface{}
+	for _, element := range elements {
+		if m, ok := element.(map[string]interface{}); ok && len(m) == 1 && m["name"] != nil {
+			name = fmt.Sprint(m["name"])
+			continue
+		}
+		rest = append(rest, element)
+	}
+	pattern, err := b.sequence(rest)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "(" + pattern + ")", nil
+	}
+	if b.groupNames[name] {
+		return "", fmt.Errorf("Regex: there is more than one group named '%s'", name)
+	}
+	if b.groupNames == nil {
+		b.groupNames = make(map[string]bool)
+	}
+	b.groupNames[name] = true
+	return "(?<" + name + ">" + pattern + ")", nil
+}
+
+// isSingle tells if the pattern of one element is a single character, class or group
+func isSingle(pattern string) bool {
+	switch {
+	case len([]rune(pattern)) == 1, len(pattern) == 2 && pattern[0] == '\\', pattern == `\pL`:
+		return true
+	case strings.HasPrefix(pattern, "[") || strings.HasPrefix(pattern, "("):
+		// Brackets in text are escaped, so this is a class or group that the element made
+		return true
+	}
+	return false
+}
+
+// quoteText escapes the characters that have a meaning in a regular expression outside a character class. A '}'
+// without a '{' before it is taken as it is, so it is not escaped. Brackets are.
+func quoteText(text string) string {
+	var quoted strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(`\.+*?()|[]{^$`, r) {
+			quoted.WriteRune('\\')
+		}
+		quoted.WriteRune(r)
+	}
+	return quoted.String()
+}
+
+// characterClass escapes the characters that have a meaning in a character class
+func characterClass(chars string) string {
+	var escaped strings.Builder
+	for _, c := range chars {
+		switch c {
+		case '\\', ']', '[', '^', '-':
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(c)
+	}
+	return escaped.String()
+}
+
+func unsupportedError(element, feature string) error {
+	return fmt.Errorf("Regex: '%s' is not supported, Go regular expressions (RE2) do not have %s", element, feature)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,76 @@
+package regex
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// MatchCommand represents the "Match" command
+type MatchCommand struct {
+	Text  string
+	Regex *regexp.Regexp
+	All   bool
+}
+
+// NewMatchCommand creates a new Match command from its data: the 'text' to search, the 'regex' to search for and
+// 'all' to find all matches instead of the first one
+func NewMatchCommand(data map[string]interface{}) (*MatchCommand, error) {
+	s, err := text("Match", data)
+	if err != nil {
+		return nil, err
+	}
+	re, err := Pattern("Match", data)
+	if err != nil {
+		return nil, err
+	}
+	all := false
+	if value, ok := data["all"]; ok {
+		if all, ok = value.(bool); !ok {
+			return nil, fmt.Errorf("Match: 'all' should be true or false, got: %v", value)
+		}
+	}
+	return &MatchCommand{Text: s, Regex: re, All: all}, nil
+}
+
+// Execute searches the text. A match is an object with the matched text as 'match', the named groups as
+// properties of 'groups' and all groups in order as 'captures'. A group that did not take part in the match is
+// null. Without 'all', the output is the first match, or an empty object if there is none. With 'all', it is the
+// list of matches.
+func (c *MatchCommand) Execute() interface{} {
+	if !c.All {
+		indices := c.Regex.FindStringSubmatchIndex(c.Text)
+		if indices == nil {
+			return map[string]interface{}{}
+		}
+		return c.match(indices)
+	}
+
+	matches := []interface{}{}
+	for _, indices := range c.Regex.FindAllStringSubmatchIndex(c.Text, -1) {
+		matches = append(matches, c.match(indices))
+	}
+	return matches
+}
+
+func (c *MatchCommand) match(indices []int) map[string]interface{} {
+	groups := map[string]interface{}{}
+	captures := make([]interface{}, 0, c.Regex.NumSubexp())
+	for i, name := range c.Regex.SubexpNames() {
+		if i == 0 {
+			continue
+		}
+		var value interface{}
+		if indices[2*i] >= 0 {
+			value = c.Text[indices[2*i]:indices[2*i+1]]
+		}
+		captures = append(captures, value)
+		if name != "" {
+			groups[name] = value
+		}
+	}
+	return map[string]interface{}{
+		"match":    c.Text[indices[0]:indices[1]],
+		"groups":   groups,
+		"captures": captures,
+	}
+}
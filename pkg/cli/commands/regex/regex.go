@@ -0,0 +1,70 @@
+package regex
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// Compile compiles a regular expression with Go's RE2 engine. Constructs that other engines have, like lookaheads
+// and backreferences, give an error that says they are not supported.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err == nil {
+		return re, nil
+	}
+	var syntaxErr *syntax.Error
+	if errors.As(err, &syntaxErr) {
+		if feature := unsupportedFeature(syntaxErr.Expr); feature != "" {
+			return nil, fmt.Errorf("Regex '%s' uses %s, which Go regular expressions (RE2) do not support", pattern, feature)
+		}
+	}
+	return nil, fmt.Errorf("Invalid regex '%s': %w", pattern, err)
+}
+
+// unsupportedFeature names the construct of another regex engine that a syntax error is about, if it is one
+func unsupportedFeature(expr string) string {
+	switch {
+	case strings.HasPrefix(expr, "(?="), strings.HasPrefix(expr, "(?!"):
+		return "a lookahead"
+	case strings.HasPrefix(expr, "(?<="), strings.HasPrefix(expr, "(?<!"):
+		return "a lookbehind"
+	case strings.HasPrefix(expr, "(?>"):
+		return "an atomic group"
+	case strings.HasPrefix(expr, `\k`), len(expr) == 2 && expr[0] == '\\' && expr[1] >= '1' && expr[1] <= '9':
+		return "a backreference"
+	case strings.HasSuffix(expr, "++"), strings.HasSuffix(expr, "*+"), strings.HasSuffix(expr, "?+"):
+		return "a possessive quantifier"
+	}
+	return ""
+}
+
+// Pattern gives the regex in the data of a command: a regular expression as text, or the readable form of Build
+func Pattern(command string, data map[string]interface{}) (*regexp.Regexp, error) {
+	value, ok := data["regex"]
+	if !ok {
+		return nil, fmt.Errorf("%s: missing 'regex'", command)
+	}
+	pattern, ok := value.(string)
+	if !ok {
+		var err error
+		if pattern, err = Build(value); err != nil {
+			return nil, err
+		}
+	}
+	return Compile(pattern)
+}
+
+// text gives the text in the data of a command
+func text(command string, data map[string]interface{}) (string, error) {
+	value, ok := data["text"]
+	if !ok || value == nil {
+		return "", fmt.Errorf("%s: missing 'text'", command)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
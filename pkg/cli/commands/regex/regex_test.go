@@ -0,0 +1,123 @@
+package regex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{`[text: "${", group: [one or more: [not: "}"]], text: "}"]`, `\$\{([^}]+)}`},
+		{`[start of string, text: //, zero or more: [whitespace], group: [name: comment, zero or more: [any]], end of string]`,
+			`^//\s*(?<comment>.*)$`},
+		{`[one or more: [text: ab], optional: digit]`, `(?:ab)+\d?`},
+		{`[one or more: [a]]`, ""},
+		{`[either: [[text: a.b], [any of: "-x"]], one or more: [either: [[text: a], [text: b]]]]`, `(?:a\.b|[\-x])(?:a|b)+`},
+		{`[pattern: "[a-z]+", start of line]`, `(?:[a-z]+)(?m:^)`},
+	}
+	for _, tt := range tests {
+		var data interface{}
+		if err := yaml.Unmarshal([]byte(tt.source), &data); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := Build(data)
+		if tt.expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got: %s", tt.source, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.source, err)
+		} else if actual != tt.expected {
+			t.Errorf("%s:\n  Expected: %s\n  Actual:   %s", tt.source, tt.expected, actual)
+		}
+	}
+
+	// Text matches only itself
+	text := `a{2}.*+?(x)[y]|^$\ }`
+	pattern, err := Build([]interface{}{map[string]interface{}{"text": text}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	re, err := Compile("^" + pattern + "$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !re.MatchString(text) || re.MatchString("aa") {
+		t.Errorf("Expected %s to match only %s", pattern, text)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	tests := map[string]string{
+		`foo(?=bar)`:  "uses a lookahead",
+		`(?<!a)b`:     "uses a lookbehind",
+		`(a)\1`:       "uses a backreference",
+		`(?>a)`:       "uses an atomic group",
+		`[a-`:         "Invalid regex",
+		`(?<name>a)`:  "",
+		`(?P<name>a)`: "",
+	}
+	for pattern, message := range tests {
+		_, err := Compile(pattern)
+		if message == "" {
+			if err != nil {
+				t.Errorf("%s: %v", pattern, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%s: expected error containing %q, got: %v", pattern, message, err)
+		}
+	}
+
+	if _, err := Build([]interface{}{map[string]interface{}{"followed by": "x"}}); err == nil ||
+		!strings.Contains(err.Error(), "do not have lookaheads") {
+		t.Errorf("Expected an error for a lookahead element, got: %v", err)
+	}
+}
+
+func TestMatchAndReplace(t *testing.T) {
+	data := map[string]interface{}{
+		"text":  "// TODO: fix\ncode\n// done",
+		"regex": `(?m)^//\s*(?<label>\w+)(:)?`,
+	}
+	matchCmd, err := NewMatchCommand(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"match":    "// TODO:",
+		"groups":   map[string]interface{}{"label": "TODO"},
+		"captures": []interface{}{"TODO", ":"},
+	}
+	if actual := matchCmd.Execute(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Not equal:\n  Expected: %v\n  Actual:   %v", expected, actual)
+	}
+
+	matchCmd.All = true
+	matches := matchCmd.Execute().([]interface{})
+	if len(matches) != 2 || matches[1].(map[string]interface{})["captures"].([]interface{})[1] != nil {
+		t.Errorf("Expected two matches, the second without a colon, got: %v", matches)
+	}
+
+	matchCmd.Text = "nothing"
+	matchCmd.All = false
+	if actual := matchCmd.Execute(); !reflect.DeepEqual(actual, map[string]interface{}{}) {
+		t.Errorf("Expected an empty object, got: %v", actual)
+	}
+
+	data["with"] = "# $label"
+	replaceCmd, err := NewReplaceCommand(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := replaceCmd.Execute(); actual != "# TODO fix\ncode\n# done" {
+		t.Errorf("Unexpected replacement: %q", actual)
+	}
+}
@@ -0,0 +1,37 @@
+package regex
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ReplaceCommand represents the "Replace with regex" command
+type ReplaceCommand struct {
+	Text  string
+	Regex *regexp.Regexp
+	With  string
+}
+
+// NewReplaceCommand creates a new Replace with regex command from its data: the 'text' to change, the 'regex' to
+// search for and what to replace the matches 'with'
+func NewReplaceCommand(data map[string]interface{}) (*ReplaceCommand, error) {
+	s, err := text("Replace with regex", data)
+	if err != nil {
+		return nil, err
+	}
+	re, err := Pattern("Replace with regex", data)
+	if err != nil {
+		return nil, err
+	}
+	with, ok := data["with"]
+	if !ok || with == nil {
+		return nil, fmt.Errorf("Replace with regex: missing 'with'")
+	}
+	return &ReplaceCommand{Text: s, Regex: re, With: fmt.Sprint(with)}, nil
+}
+
+// Execute replaces all matches in the text. In the replacement, $1 or $name is the text of a group, and $$ is a
+// dollar sign.
+func (c *ReplaceCommand) Execute() string {
+	return c.Regex.ReplaceAllString(c.Text, c.With)
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Command is a single command in a script: the command name with its data
+type Command struct {
+	Name string
+	Data interface{}
+	// Line is the line in the script where the command starts
+	Line int
+	// Node is the YAML source of the data. It keeps the order of object keys, which Data does not.
+	Node *yaml.Node
+}
+
+// HandlerFunc executes a command with its data. It returns the output of the command, or nil if there is none.
+type HandlerFunc func(ctx *ExecutionContext, data interface{}) (interface{}, error)
+
+// Definition describes a command that can be used in scripts
+type Definition struct {
+	Name string
+	// Namespace is the directory of the command in the spec, for example instacli/testing.
+	// The command data is validated against the schema in its 'schema' subdirectory.
+	Namespace string
+	// Schema overrides the name of the schema file, if it is not named after the command
+	Schema string
+	// Builtin tells that the command comes with Instacli, so a script can not define a command with its name
+	Builtin bool
+	// HandlesLists tells if the command takes a list as data. Other commands are run for each item in a list.
+	HandlesLists bool
+	// DelayedResolver tells that variables in the data should not be resolved before running the command,
+	// for example because the data contains other commands.
+	DelayedResolver bool
+	// PipeProperty is the property of the data that the output of the previous command fills, when it is piped
+	// into the command with '=>'
+	PipeProperty string
+	// Validate checks the data of a command that does not have a schema in the spec. It returns the problems found.
+	Validate func(data interface{}) ([]string, error)
+	Handler  HandlerFunc
+}
+
+// SchemaName returns the name of the schema file of the command, without the .schema.yaml extension
+func (d *Definition) SchemaName() string {
+	if d.Schema != "" {
+		return d.Schema
+	}
+	return d.Name
+}
+
+// Registry holds the commands that are available to a script
+type Registry struct {
+	commands map[string]*Definition
+}
+
+// NewRegistry creates an empty command registry
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]*Definition)}
+}
+
+// Register adds a command to the registry, replacing any command with the same name
+func (r *Registry) Register(def *Definition) {
+	r.commands[def.Name] = def
+}
+
+// MarkBuiltin marks all commands in the registry as built-in commands
+func (r *Registry) MarkBuiltin() {
+	for _, def := range r.commands {
+		def.Builtin = true
+	}
+}
+
+// Get returns the command with the given name, or nil if it is not known
+func (r *Registry) Get(name string) *Definition {
+	return r.commands[name]
+}
+
+// IsVariableAssignment checks if a command name has the form ${var}, which assigns its data to a variable
+func IsVariableAssignment(name string) bool {
+	return strings.HasPrefix(name, "${") && strings.HasSuffix(name, "}")
+}
+
+// ObjectData checks that the data of a command is an object
+func ObjectData(command string, data interface{}) (map[string]interface{}, error) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Command '%s' expects an object, got: %v", command, data)
+	}
+	return m, nil
+}
+
+// ObjectKeys returns the keys of an object in the order of the YAML source, if there is one.
+// Otherwise the keys are sorted.
+func ObjectKeys(node *yaml.Node, data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	if node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if _, ok := data[node.Content[i].Value]; ok {
+				keys = append(keys, node.Content[i].Value)
+			}
+		}
+		if len(keys) == len(data) {
+			return keys
+		}
+		keys = keys[:0]
+	}
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CommandFormatError is returned when the data of a command does not match the schema of the command
+type CommandFormatError struct {
+	Command  string
+	Line     int
+	Problems []string
+}
+
+func (e *CommandFormatError) Error() string {
+	return fmt.Sprintf("Invalid command format for '%s' on line %d:\n  %s", e.Command, e.Line, strings.Join(e.Problems, "\n  "))
+}
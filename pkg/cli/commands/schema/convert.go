@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"fmt"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TypeToJSONSchemaCommand represents the "Type to JSON schema" command
+type TypeToJSONSchemaCommand struct {
+	Type *types.Type
+}
+
+// NewTypeToJSONSchemaCommand creates a new Type to JSON schema command from a type name or definition
+func NewTypeToJSONSchemaCommand(data interface{}) (*TypeToJSONSchemaCommand, error) {
+	t, err := types.FromData(data)
+	if err != nil {
+		return nil, err
+	}
+	return &TypeToJSONSchemaCommand{Type: t}, nil
+}
+
+// Execute converts the type to JSON Schema, using the types known to the script for references
+func (c *TypeToJSONSchemaCommand) Execute(ctx *commands.ExecutionContext) (map[string]interface{}, error) {
+	return ctx.Types().ToJSONSchema(c.Type)
+}
+
+// JSONSchemaToTypeCommand represents the "JSON schema to type" command
+type JSONSchemaToTypeCommand struct {
+	Schema map[string]interface{}
+}
+
+// NewJSONSchemaToTypeCommand creates a new JSON schema to type command
+func NewJSONSchemaToTypeCommand(data map[string]interface{}) *JSONSchemaToTypeCommand {
+	return &JSONSchemaToTypeCommand{Schema: data}
+}
+
+// Execute converts the schema to a type definition. Definitions in the schema are registered as types,
+// so they can be used by the rest of the script.
+func (c *JSONSchemaToTypeCommand) Execute(ctx *commands.ExecutionContext) (interface{}, error) {
+	t, err := ctx.Types().FromJSONSchema(c.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("can not convert schema to type: %w", err)
+	}
+
+	// Return the definition as plain data, the way it would be written in types.yaml
+	source, err := yaml.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	var definition interface{}
+	if err := yaml.Unmarshal(source, &definition); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/jsonschema"
+)
+
+// ValidateSchemaCommand represents the "Validate schema" command
+type ValidateSchemaCommand struct {
+	Data interface{}
+	// Schema is either an inline schema or the name of a schema file relative to the script
+	Schema interface{}
+}
+
+// NewValidateSchemaCommand creates a new Validate schema command
+func NewValidateSchemaCommand(data map[string]interface{}) (*ValidateSchemaCommand, error) {
+	cmd := &ValidateSchemaCommand{Data: data["data"]}
+
+	schema, ok := data["schema"]
+	if !ok {
+		return nil, fmt.Errorf("missing required parameter: schema")
+	}
+	switch schema.(type) {
+	case string, map[string]interface{}:
+		cmd.Schema = schema
+	default:
+		return nil, fmt.Errorf("'schema' must be a schema or the name of a schema file")
+	}
+
+	return cmd, nil
+}
+
+// Execute runs the Validate schema command. It fails with all validation errors if the data is invalid.
+func (c *ValidateSchemaCommand) Execute(ctx *commands.ExecutionContext) error {
+	loader := jsonschema.NewLoader()
+
+	var schema *jsonschema.Schema
+	var err error
+	if file, ok := c.Schema.(string); ok {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(ctx.ScriptDir(), file)
+		}
+		schema, err = loader.Load(file)
+	} else {
+		schema, err = loader.Compile(c.Schema, ctx.ScriptDir())
+	}
+	if err != nil {
+		return err
+	}
+
+	return schema.Validate(c.Data)
+}
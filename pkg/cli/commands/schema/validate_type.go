@@ -0,0 +1,36 @@
+package schema
+
+import (
+	"fmt"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/types"
+)
+
+// ValidateTypeCommand represents the "Validate type" command
+type ValidateTypeCommand struct {
+	Item interface{}
+	Type *types.Type
+}
+
+// NewValidateTypeCommand creates a new Validate type command
+func NewValidateTypeCommand(data map[string]interface{}) (*ValidateTypeCommand, error) {
+	cmd := &ValidateTypeCommand{Item: data["item"]}
+
+	typeData, ok := data["type"]
+	if !ok {
+		return nil, fmt.Errorf("missing required parameter: type")
+	}
+	t, err := types.FromData(typeData)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Type = t
+
+	return cmd, nil
+}
+
+// Execute runs the Validate type command. It fails with all violations if the item does not match the type.
+func (c *ValidateTypeCommand) Execute(ctx *commands.ExecutionContext) error {
+	return ctx.Types().Check(c.Item, c.Type)
+}
@@ -2,10 +2,8 @@ package testing
 
 import (
 	"fmt"
-	"reflect"
-	"strings"
 
-	"gopkg.in/yaml.v3"
+	"instacli/pkg/cli/commands"
 )
 
 // AssertEqualsCommand represents the "Assert equals" command
@@ -14,15 +12,10 @@ type AssertEqualsCommand struct {
 	Expected interface{} `yaml:"expected"`
 }
 
-// Execute runs the Assert equals command
-func (c *AssertEqualsCommand) Execute() error {
-	if !reflect.DeepEqual(c.Actual, c.Expected) {
-		actualYAML, _ := yaml.Marshal(c.Actual)
-		expectedYAML, _ := yaml.Marshal(c.Expected)
-		// Trim any trailing newlines from the YAML output
-		actualStr := strings.TrimSpace(string(actualYAML))
-		expectedStr := strings.TrimSpace(string(expectedYAML))
-		return fmt.Errorf("Not equal:\n  Expected: %s\n  Actual:   %s", expectedStr, actualStr)
+// Execute runs the Assert equals command. Only the paths that differ are shown, colored if the console is a terminal.
+func (c *AssertEqualsCommand) Execute(ctx *commands.ExecutionContext) error {
+	if diffs := Compare(c.Expected, c.Actual); len(diffs) > 0 {
+		return fmt.Errorf("Not equal:\n%s", FormatDifferences(diffs, ctx.Console().Color()))
 	}
 	return nil
 }
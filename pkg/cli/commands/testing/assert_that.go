@@ -3,9 +3,8 @@ package testing
 import (
 	"fmt"
 	"reflect"
-	"strings"
 
-	"gopkg.in/yaml.v3"
+	"instacli/pkg/values"
 )
 
 // AssertThatCommand represents the "Assert that" command
@@ -24,25 +23,15 @@ func (c *AssertThatCommand) Execute() error {
 	// Check for empty condition
 	if c.Empty != nil {
 		if !isEmpty(c.Empty) {
-			emptyYAML, _ := yaml.Marshal(c.Empty)
-			emptyStr := strings.TrimSpace(string(emptyYAML))
-			if strings.HasPrefix(emptyStr, "-") {
-				// Convert YAML list format to array format
-				emptyStr = "[" + strings.Join(strings.Split(strings.TrimSpace(strings.ReplaceAll(emptyStr, "- ", "")), "\n"), ", ") + "]"
-			}
-			return fmt.Errorf("Condition is false.\nEmpty: %s", emptyStr)
+			return fmt.Errorf("Condition is false.\nEmpty: %s", formatValue(c.Empty))
 		}
 		return nil
 	}
 
 	// Check for equals condition
 	if c.Item != nil && c.Equals != nil {
-		if !reflect.DeepEqual(c.Item, c.Equals) {
-			itemYAML, _ := yaml.Marshal(c.Item)
-			equalsYAML, _ := yaml.Marshal(c.Equals)
-			return fmt.Errorf("Condition is false.\nItem: %s\nEquals: %s",
-				strings.TrimSpace(string(itemYAML)),
-				strings.TrimSpace(string(equalsYAML)))
+		if !values.Equal(c.Item, c.Equals) {
+			return fmt.Errorf("Condition is false.\nItem: %s\nEquals: %s", formatValue(c.Item), formatValue(c.Equals))
 		}
 		return nil
 	}
@@ -50,17 +39,7 @@ func (c *AssertThatCommand) Execute() error {
 	// Check for contains condition
 	if c.Item != nil && c.In != nil {
 		if !contains(c.In, c.Item) {
-			itemYAML, _ := yaml.Marshal(c.Item)
-			inYAML, _ := yaml.Marshal(c.In)
-			// Format the error message to match the expected output
-			inStr := strings.TrimSpace(string(inYAML))
-			if strings.HasPrefix(inStr, "-") {
-				// Convert YAML list format to array format
-				inStr = "[" + strings.Join(strings.Split(strings.TrimSpace(strings.ReplaceAll(inStr, "- ", "")), "\n"), ", ") + "]"
-			}
-			return fmt.Errorf("Condition is false.\nItem: %s\nIn: %s",
-				strings.TrimSpace(string(itemYAML)),
-				inStr)
+			return fmt.Errorf("Condition is false.\nItem: %s\nIn: %s", formatValue(c.Item), formatValue(c.In))
 		}
 		return nil
 	}
@@ -140,7 +119,7 @@ func contains(container, item interface{}) bool {
 		containerMap := container.(map[string]interface{})
 		for k, v := range itemMap {
 			containerVal, exists := containerMap[k]
-			if !exists || !reflect.DeepEqual(containerVal, v) {
+			if !exists || !values.Equal(containerVal, v) {
 				return false
 			}
 		}
@@ -151,7 +130,7 @@ func contains(container, item interface{}) bool {
 	if reflect.TypeOf(container).Kind() == reflect.Slice || reflect.TypeOf(container).Kind() == reflect.Array {
 		containerVal := reflect.ValueOf(container)
 		for i := 0; i < containerVal.Len(); i++ {
-			if reflect.DeepEqual(containerVal.Index(i).Interface(), item) {
+			if values.Equal(containerVal.Index(i).Interface(), item) {
 				return true
 			}
 		}
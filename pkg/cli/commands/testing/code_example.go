@@ -0,0 +1,17 @@
+package testing
+
+import "instacli/pkg/cli/commands"
+
+type CodeExampleCommand struct{}
+
+func NewCodeExampleCommand() *CodeExampleCommand {
+	return &CodeExampleCommand{}
+}
+
+// Execute marks the start of an example: console output is checked from here on. Variables are kept, so the
+// example sees the ones that hidden code before it in the same section sets. Examples are kept apart by running each
+// test case in a context of its own.
+func (c *CodeExampleCommand) Execute(ctx *commands.ExecutionContext) error {
+	ctx.Console().StartCapture()
+	return nil
+}
@@ -0,0 +1,142 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"instacli/pkg/values"
+)
+
+// Difference is a place where the actual value does not match the expected one
+type Difference struct {
+	// Path points to the value, for example $.items[3].name
+	Path     string
+	Expected interface{}
+	Actual   interface{}
+	// Missing tells that the expected value is not there
+	Missing bool
+	// Unexpected tells that the actual value was not expected at all
+	Unexpected bool
+}
+
+// Format shows the difference on a single line. With color, the expected value is green and the actual value red.
+func (d Difference) Format(color bool) string {
+	expected := paint(formatValue(d.Expected), "32", color)
+	actual := paint(formatValue(d.Actual), "31", color)
+	switch {
+	case d.Missing:
+		return fmt.Sprintf("%s: expected %s, but it is missing", d.Path, expected)
+	case d.Unexpected:
+		return fmt.Sprintf("%s: unexpected %s", d.Path, actual)
+	default:
+		return fmt.Sprintf("%s: expected %s, got %s", d.Path, expected, actual)
+	}
+}
+
+// Compare returns the differences between the expected and the actual value, with the paths sorted within each
+// object. Objects are equal if they have the same properties, regardless of order. Scalars are compared with
+// values.Equal.
+func Compare(expected, actual interface{}) []Difference {
+	var diffs []Difference
+	compare("$", expected, actual, &diffs)
+	return diffs
+}
+
+// FormatDifferences shows each difference on a line of its own, indented
+func FormatDifferences(diffs []Difference, color bool) string {
+	lines := make([]string, len(diffs))
+	for i, d := range diffs {
+		lines[i] = "  " + d.Format(color)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func compare(path string, expected, actual interface{}, diffs *[]Difference) {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(e, a) {
+			expectedValue, inExpected := e[key]
+			actualValue, inActual := a[key]
+			keyPath := path + pathKey(key)
+			switch {
+			case !inActual:
+				*diffs = append(*diffs, Difference{Path: keyPath, Expected: expectedValue, Missing: true})
+			case !inExpected:
+				*diffs = append(*diffs, Difference{Path: keyPath, Actual: actualValue, Unexpected: true})
+			default:
+				compare(keyPath, expectedValue, actualValue, diffs)
+			}
+		}
+		return
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(e) || i < len(a); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(a):
+				*diffs = append(*diffs, Difference{Path: itemPath, Expected: e[i], Missing: true})
+			case i >= len(e):
+				*diffs = append(*diffs, Difference{Path: itemPath, Actual: a[i], Unexpected: true})
+			default:
+				compare(itemPath, e[i], a[i], diffs)
+			}
+		}
+		return
+	default:
+		if values.Equal(expected, actual) {
+			return
+		}
+	}
+	*diffs = append(*diffs, Difference{Path: path, Expected: expected, Actual: actual})
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// pathKey returns the path segment of an object property, like .name or ["first name"]
+func pathKey(key string) string {
+	if identifier.MatchString(key) {
+		return "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return "[" + string(quoted) + "]"
+}
+
+func sortedKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatValue shows a value as compact JSON, so strings are quoted and types can be told apart
+func formatValue(v interface{}) string {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(out)
+}
+
+func paint(text, code string, color bool) string {
+	if !color {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
@@ -0,0 +1,52 @@
+package testing
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		diffs    []string
+	}{
+		{"Equal objects in a different order", "{a: 1, b: [x, y]}", "{b: [x, y], a: 1}", nil},
+		{"Number is not text", "{count: 1}", `{count: "1"}`, []string{`$.count: expected 1, got "1"`}},
+		{"Integer equals float", "1", "1.0", nil},
+		{"Boolean is not text", "true", `"true"`, []string{`$: expected true, got "true"`}},
+		{"Texts are not numbers", `"1"`, `"1.0"`, []string{`$: expected "1", got "1.0"`}},
+		{"Nested path", "{items: [{name: a}, {name: a}]}", "{items: [{name: a}, {name: b}]}",
+			[]string{`$.items[1].name: expected "a", got "b"`}},
+		{"Missing and unexpected properties", "{name: a, first name: b}", "{name: a, age: 3}", []string{
+			`$.age: unexpected 3`,
+			`$["first name"]: expected "b", but it is missing`,
+		}},
+		{"List lengths", "[1, 2]", "[1]", []string{`$[1]: expected 2, but it is missing`}},
+		{"Different types", "{a: 1}", "[1]", []string{`$: expected {"a":1}, got [1]`}},
+		{"Null", "{a: null}", "{a: 0}", []string{`$.a: expected null, got 0`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diffs []string
+			for _, d := range Compare(parse(t, tt.expected), parse(t, tt.actual)) {
+				diffs = append(diffs, d.Format(false))
+			}
+			if strings.Join(diffs, "\n") != strings.Join(tt.diffs, "\n") {
+				t.Errorf("Not equal:\n  Expected: %v\n  Actual:   %v", tt.diffs, diffs)
+			}
+		})
+	}
+}
+
+func parse(t *testing.T, source string) interface{} {
+	t.Helper()
+	var data interface{}
+	if err := yaml.Unmarshal([]byte(source), &data); err != nil {
+		t.Fatalf("invalid test data: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,95 @@
+package testing
+
+import (
+	"fmt"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/values"
+)
+
+// ExpectedConsoleOutputCommand represents the "Expected console output" command
+type ExpectedConsoleOutputCommand struct {
+	Expected interface{}
+}
+
+func NewExpectedConsoleOutputCommand(expected interface{}) *ExpectedConsoleOutputCommand {
+	return &ExpectedConsoleOutputCommand{Expected: expected}
+}
+
+// Execute compares the console output since the start of the test case with the expected text.
+// Trailing whitespace on each line and leading and trailing empty lines are ignored.
+func (c *ExpectedConsoleOutputCommand) Execute(ctx *commands.ExecutionContext) error {
+	expected, ok := c.Expected.(string)
+	if !ok {
+		out, err := values.YAML(c.Expected)
+		if err != nil {
+			return err
+		}
+		expected = out
+	}
+
+	actual, capturing := ctx.Console().Captured()
+	if !capturing {
+		actual = "<no output>"
+	}
+
+	expectedLines := normalizeLines(expected)
+	actualLines := normalizeLines(actual)
+	if strings.Join(expectedLines, "\n") != strings.Join(actualLines, "\n") {
+		return fmt.Errorf("Unexpected console output.\n%s", strings.Join(diffLines(expectedLines, actualLines), "\n"))
+	}
+	return nil
+}
+
+// normalizeLines splits text into lines, without trailing whitespace and without empty lines at the start and end
+func normalizeLines(text string) []string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines shows the difference between expected and actual lines. Lines that are only expected
+// start with '-', lines that are only in the actual output start with '+'.
+func diffLines(expected, actual []string) []string {
+	// Longest common subsequence, computed from the end so the diff can be read from the start
+	lcs := make([][]int, len(expected)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(actual)+1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if expected[i] == actual[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i] == actual[j]:
+			diff = append(diff, "    "+expected[i])
+			i++
+			j++
+		case i < len(expected) && (j == len(actual) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, "  - "+expected[i])
+			i++
+		default:
+			diff = append(diff, "  + "+actual[j])
+			j++
+		}
+	}
+	return diff
+}
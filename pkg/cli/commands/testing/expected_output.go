@@ -3,10 +3,7 @@ package testing
 import (
 	"fmt"
 	"instacli/pkg/cli/commands"
-	"reflect"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 type ExpectedOutputCommand struct {
@@ -17,12 +14,18 @@ func NewExpectedOutputCommand(expected interface{}) *ExpectedOutputCommand {
 	return &ExpectedOutputCommand{Expected: expected}
 }
 
+// Execute compares the output with the expected value. Only the paths that differ are shown, colored if the
+// console is a terminal. Text is compared without leading and trailing whitespace.
 func (c *ExpectedOutputCommand) Execute(ctx *commands.ExecutionContext) error {
-	actual := ctx.GetOutput()
-	if !reflect.DeepEqual(actual, c.Expected) {
-		actualYAML, _ := yaml.Marshal(actual)
-		expectedYAML, _ := yaml.Marshal(c.Expected)
-		return fmt.Errorf("Unexpected output.\n  Expected: %s\n  Actual:   %s", strings.TrimSpace(string(expectedYAML)), strings.TrimSpace(string(actualYAML)))
+	expected, actual := c.Expected, ctx.GetOutput()
+	if e, ok := expected.(string); ok {
+		expected = strings.TrimSpace(e)
+	}
+	if a, ok := actual.(string); ok {
+		actual = strings.TrimSpace(a)
+	}
+	if diffs := Compare(expected, actual); len(diffs) > 0 {
+		return fmt.Errorf("Unexpected output.\n%s", FormatDifferences(diffs, ctx.Console().Color()))
 	}
 	return nil
 }
@@ -1,12 +1,15 @@
 package testing
 
+import "instacli/pkg/cli/commands"
+
 type TestCaseCommand struct{}
 
 func NewTestCaseCommand() *TestCaseCommand {
 	return &TestCaseCommand{}
 }
 
-func (c *TestCaseCommand) Execute() error {
-	// No-op: used as a marker for test boundaries
+// Execute marks the start of a test case. Console output is checked from here on.
+func (c *TestCaseCommand) Execute(ctx *commands.ExecutionContext) error {
+	ctx.Console().StartCapture()
 	return nil
 }
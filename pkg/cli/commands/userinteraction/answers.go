@@ -0,0 +1,26 @@
+package userinteraction
+
+import (
+	"instacli/pkg/cli/commands"
+)
+
+// AnswersCommand represents the "Answers" command
+type AnswersCommand struct {
+	Answers map[string]interface{}
+}
+
+// NewAnswersCommand creates a new Answers command with answers by question
+func NewAnswersCommand(data map[string]interface{}) *AnswersCommand {
+	return &AnswersCommand{Answers: data}
+}
+
+// Execute records the answers, so prompts with these questions are answered without asking the user
+func (c *AnswersCommand) Execute(ctx *commands.ExecutionContext) error {
+	recorded, ok := ctx.UserInteraction().(*RecordedAnswers)
+	if !ok {
+		recorded = NewRecordedAnswers(ctx.UserInteraction(), ctx.Console())
+		ctx.SetUserInteraction(recorded)
+	}
+	recorded.Record(c.Answers)
+	return nil
+}
@@ -0,0 +1,36 @@
+package userinteraction
+
+import (
+	"fmt"
+
+	"instacli/pkg/cli/commands"
+)
+
+// Answers to a confirmation question
+const (
+	Yes = "Yes"
+	No  = "No"
+)
+
+// ConfirmCommand represents the "Confirm" command
+type ConfirmCommand struct {
+	Question string
+}
+
+// NewConfirmCommand creates a new Confirm command
+func NewConfirmCommand(data interface{}) *ConfirmCommand {
+	return &ConfirmCommand{Question: displayText(data)}
+}
+
+// Execute asks the user to confirm the question. It fails if the user does not confirm.
+func (c *ConfirmCommand) Execute(ctx *commands.ExecutionContext) (interface{}, error) {
+	question := &ParameterData{Description: c.Question, Enum: []interface{}{Yes, No}}
+	answer, err := question.Prompt(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if answer != Yes {
+		return nil, fmt.Errorf("No confirmation -- action canceled.")
+	}
+	return answer, nil
+}
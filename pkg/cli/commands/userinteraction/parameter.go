@@ -0,0 +1,214 @@
+package userinteraction
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/commands/testing"
+	"instacli/pkg/cli/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Values of 'select'
+const (
+	SelectSingle   = "single"
+	SelectMultiple = "multiple"
+)
+
+// ParameterData describes a question to the user: how to ask it and what kind of answer is expected
+type ParameterData struct {
+	Description     string                 `yaml:"description,omitempty"`
+	Default         interface{}            `yaml:"default,omitempty"`
+	Type            *types.Type            `yaml:"type,omitempty"`
+	Secret          bool                   `yaml:"secret,omitempty"`
+	Select          string                 `yaml:"select,omitempty"`
+	Enum            []interface{}          `yaml:"enum,omitempty"`
+	DisplayProperty string                 `yaml:"display property,omitempty"`
+	ValueProperty   string                 `yaml:"value property,omitempty"`
+	Condition       map[string]interface{} `yaml:"condition,omitempty"`
+	ShortOption     string                 `yaml:"short option,omitempty"`
+}
+
+// NewParameterData creates parameter data from a description or a full definition
+func NewParameterData(data interface{}) (*ParameterData, error) {
+	if description, ok := data.(string); ok {
+		return &ParameterData{Description: description}, nil
+	}
+	source, err := yaml.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	p := &ParameterData{}
+	if err := yaml.Unmarshal(source, p); err != nil {
+		return nil, fmt.Errorf("invalid parameter definition: %w", err)
+	}
+	// Values are kept as they are given, so objects keep the order of their keys
+	if m, ok := data.(map[string]interface{}); ok {
+		if enum, ok := m["enum"].([]interface{}); ok {
+			p.Enum = enum
+		}
+		if value, ok := m["default"]; ok {
+			p.Default = value
+		}
+	}
+	return p, nil
+}
+
+// ConditionValid tells if the question should be asked. It is true if there is no condition.
+func (p *ParameterData) ConditionValid() (bool, error) {
+	if p.Condition == nil {
+		return true, nil
+	}
+	condition, err := testing.NewAssertThat(p.Condition)
+	if err != nil {
+		return false, err
+	}
+	return condition.Execute() == nil, nil
+}
+
+// Prompt asks the question through the user interaction of the context and returns the answer.
+// The label is used if there is no description.
+func (p *ParameterData) Prompt(ctx *commands.ExecutionContext, label string) (interface{}, error) {
+	message := p.Description
+	if message == "" {
+		message = label
+	}
+
+	ui := ctx.UserInteraction()
+	switch {
+	case p.Enum != nil:
+		return p.promptChoice(ui, message)
+	case p.Secret:
+		return ui.Prompt(message, p.defaultText(), true)
+	case p.Type != nil:
+		return p.promptByType(ctx, message, p.Type)
+	default:
+		return ui.Prompt(message, p.defaultText(), false)
+	}
+}
+
+func (p *ParameterData) defaultText() string {
+	if p.Default == nil {
+		return ""
+	}
+	return displayText(p.Default)
+}
+
+func (p *ParameterData) promptChoice(ui commands.UserInteraction, message string) (interface{}, error) {
+	choices := make([]commands.Choice, 0, len(p.Enum))
+	for _, item := range p.Enum {
+		display := displayText(item)
+		if p.DisplayProperty != "" {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("'display property' can only be used with objects in 'enum', got: %s", display)
+			}
+			display = displayText(object[p.DisplayProperty])
+		}
+		choices = append(choices, commands.Choice{Display: display, Value: item})
+	}
+
+	multiple := p.Select == SelectMultiple
+	selected, err := ui.Select(message, choices, p.Default, multiple)
+	if err != nil {
+		return nil, err
+	}
+
+	if !multiple {
+		return p.valueOf(selected[0].Value), nil
+	}
+	answers := make([]interface{}, 0, len(selected))
+	for _, c := range selected {
+		answers = append(answers, p.valueOf(c.Value))
+	}
+	return answers, nil
+}
+
+// valueOf returns the field given by 'value property' of a selected object, or the object itself
+func (p *ParameterData) valueOf(item interface{}) interface{} {
+	if object, ok := item.(map[string]interface{}); ok && p.ValueProperty != "" {
+		return object[p.ValueProperty]
+	}
+	return item
+}
+
+func (p *ParameterData) promptByType(ctx *commands.ExecutionContext, message string, t *types.Type) (interface{}, error) {
+	def, err := ctx.Types().Resolve(t)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case def.Properties != nil:
+		return promptProperties(ctx, def.Properties)
+	case def.ListOf != nil:
+		return promptList(ctx, message, def.ListOf)
+	}
+
+	value, err := ctx.UserInteraction().Prompt(message, p.defaultText(), false)
+	if err != nil {
+		return nil, err
+	}
+	answer := displayText(value)
+	switch def.Base {
+	case types.Boolean:
+		return answer == "true" || strings.EqualFold(answer, "yes"), nil
+	case types.Number:
+		if n, err := strconv.Atoi(answer); err == nil {
+			return n, nil
+		}
+		n, err := strconv.ParseFloat(answer, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number", answer)
+		}
+		return n, nil
+	case types.String, "":
+		return answer, nil
+	default:
+		return nil, fmt.Errorf("Base type not supported: %s", def.Base)
+	}
+}
+
+// promptProperties asks for each property of an object type
+func promptProperties(ctx *commands.ExecutionContext, properties types.ObjectProperties) (map[string]interface{}, error) {
+	answers := make(map[string]interface{})
+	for _, prop := range properties {
+		question := &ParameterData{Description: prop.Description, Default: prop.Default, Type: prop.Type}
+		answer, err := question.Prompt(ctx, prop.Name)
+		if err != nil {
+			return nil, err
+		}
+		answers[prop.Name] = answer
+	}
+	return answers, nil
+}
+
+// promptList asks for new items until the user is done
+func promptList(ctx *commands.ExecutionContext, message string, itemType *types.Type) ([]interface{}, error) {
+	name := itemType.Name
+	if name == "" {
+		name = "item"
+	}
+	add := "Add new " + name
+	done := "Done"
+	menu := &ParameterData{Enum: []interface{}{add, done}}
+
+	list := []interface{}{}
+	for {
+		selected, err := menu.promptChoice(ctx.UserInteraction(), message)
+		if err != nil {
+			return nil, err
+		}
+		if selected == done {
+			return list, nil
+		}
+		item, err := (&ParameterData{Type: itemType}).promptByType(ctx, "Enter new item", itemType)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+}
@@ -0,0 +1,28 @@
+package userinteraction
+
+import (
+	"instacli/pkg/cli/commands"
+)
+
+// PromptCommand represents the "Prompt" command
+type PromptCommand struct {
+	Parameter *ParameterData
+}
+
+// NewPromptCommand creates a new Prompt command from a question or a parameter definition
+func NewPromptCommand(data interface{}) (*PromptCommand, error) {
+	parameter, err := NewParameterData(data)
+	if err != nil {
+		return nil, err
+	}
+	return &PromptCommand{Parameter: parameter}, nil
+}
+
+// Execute asks the user and returns the answer. Nothing is asked if the condition is false.
+func (c *PromptCommand) Execute(ctx *commands.ExecutionContext) (interface{}, error) {
+	valid, err := c.Parameter.ConditionValid()
+	if err != nil || !valid {
+		return nil, err
+	}
+	return c.Parameter.Prompt(ctx, "")
+}
@@ -0,0 +1,60 @@
+package userinteraction
+
+import (
+	"fmt"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/commands/variables"
+)
+
+// PromptObjectCommand represents the "Prompt object" command
+type PromptObjectCommand struct {
+	// Parameters holds the unresolved definition of each question
+	Parameters map[string]interface{}
+	// Order holds the names of the questions in the order they are asked
+	Order []string
+}
+
+// NewPromptObjectCommand creates a new Prompt object command that asks the questions in the given order
+func NewPromptObjectCommand(data map[string]interface{}, order []string) *PromptObjectCommand {
+	return &PromptObjectCommand{Parameters: data, Order: order}
+}
+
+// Execute asks all questions and returns the answers as an object. Questions can refer to the answers
+// of previous questions as variables, for example in a condition.
+func (c *PromptObjectCommand) Execute(ctx *commands.ExecutionContext) (map[string]interface{}, error) {
+	answers := make(map[string]interface{})
+
+	vars := make(map[string]interface{}, len(ctx.Vars()))
+	for name, value := range ctx.Vars() {
+		vars[name] = value
+	}
+
+	for _, name := range c.Order {
+		resolved, err := variables.ResolveVariablesRecursive(c.Parameters[name], vars)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving variables in '%s': %w", name, err)
+		}
+		parameter, err := NewParameterData(resolved)
+		if err != nil {
+			return nil, err
+		}
+
+		valid, err := parameter.ConditionValid()
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			continue
+		}
+
+		answer, err := parameter.Prompt(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers[name] = answer
+		vars[name] = answer
+	}
+
+	return answers, nil
+}
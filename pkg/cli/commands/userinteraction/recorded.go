@@ -0,0 +1,139 @@
+package userinteraction
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/values"
+)
+
+// RecordedAnswers answers prompts with answers that were recorded by the Answers command, so interactive scripts
+// can be tested. Questions without a recorded answer are passed on to the fallback, if there is one.
+// The question and answer are printed the way they would look on a terminal.
+type RecordedAnswers struct {
+	answers  map[string]interface{}
+	fallback commands.UserInteraction
+	out      io.Writer
+}
+
+// NewRecordedAnswers creates a user interaction with recorded answers that prints to out. Fallback may be nil,
+// in which case questions without an answer take their default value or fail.
+func NewRecordedAnswers(fallback commands.UserInteraction, out io.Writer) *RecordedAnswers {
+	return &RecordedAnswers{answers: make(map[string]interface{}), fallback: fallback, out: out}
+}
+
+// Record adds answers, by question
+func (r *RecordedAnswers) Record(answers map[string]interface{}) {
+	for question, answer := range answers {
+		r.answers[question] = answer
+	}
+}
+
+// Prompt returns the recorded answer to the question
+func (r *RecordedAnswers) Prompt(message string, defaultValue string, password bool) (interface{}, error) {
+	answer, ok := r.answers[message]
+	if !ok {
+		switch {
+		case r.fallback != nil:
+			return r.fallback.Prompt(message, defaultValue, password)
+		case defaultValue != "":
+			answer = defaultValue
+		default:
+			return nil, fmt.Errorf("Can not ask '%s' in non-interactive mode", message)
+		}
+	}
+
+	shown := displayText(answer)
+	if password {
+		shown = "********"
+	}
+	fmt.Fprintln(r.out, renderInput(message, shown))
+
+	return answer, nil
+}
+
+// Select returns the choices that match the recorded answer, or the default if there is none
+func (r *RecordedAnswers) Select(message string, choices []commands.Choice, defaultValue interface{}, multiple bool) ([]commands.Choice, error) {
+	answer, ok := r.answers[message]
+	if !ok {
+		switch {
+		case r.fallback != nil:
+			return r.fallback.Select(message, choices, defaultValue, multiple)
+		case defaultValue != nil:
+			answer = defaultValue
+		default:
+			return nil, fmt.Errorf("No prerecorded answer for '%s'", message)
+		}
+	}
+
+	selected, missing := findChoices(answer, choices, multiple)
+	if selected == nil {
+		return nil, fmt.Errorf("Prerecorded choice '%s' not found in provided list.", missing)
+	}
+	fmt.Fprintln(r.out, renderChoices(message, choices, selected))
+
+	return selected, nil
+}
+
+// findChoices returns the choices of an answer, by the text that is shown for them or by their value. With
+// multiple, the answer can be a list. If a part of the answer is not a choice, it returns nil and the text of that
+// part.
+func findChoices(answer interface{}, choices []commands.Choice, multiple bool) ([]commands.Choice, string) {
+	answers := []interface{}{answer}
+	if list, ok := answer.([]interface{}); ok && multiple {
+		answers = list
+	}
+
+	var selected []commands.Choice
+	for _, a := range answers {
+		found := false
+		for _, c := range choices {
+			if c.Display == displayText(a) || values.Equal(c.Value, a) {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, displayText(a)
+		}
+	}
+	return selected, ""
+}
+
+func renderInput(message string, answer string) string {
+	return "? " + message + " " + answer
+}
+
+// renderChoices shows the list of choices with the selected ones marked, and ends with an empty line. For example:
+//
+//	? Select ingredients
+//	 ❯ ◉ Apple
+//	   ◯ Banana
+func renderChoices(message string, choices []commands.Choice, selected []commands.Choice) string {
+	var out strings.Builder
+	out.WriteString("? " + message + " \n")
+	first := true
+	for _, c := range choices {
+		isSelected := false
+		for _, s := range selected {
+			if s.Display == c.Display {
+				isSelected = true
+				break
+			}
+		}
+		switch {
+		case isSelected && first:
+			out.WriteString(" ❯ ◉ ")
+			first = false
+		case isSelected:
+			out.WriteString("   ◉ ")
+		default:
+			out.WriteString("   ◯ ")
+		}
+		out.WriteString(c.Display + "\n")
+	}
+	return out.String()
+}
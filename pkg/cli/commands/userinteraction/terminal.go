@@ -0,0 +1,142 @@
+package userinteraction
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/values"
+
+	"golang.org/x/term"
+)
+
+// stdin is shared by all prompts, so input that was read ahead is not lost between prompts
+var stdin = bufio.NewReader(os.Stdin)
+
+// Terminal asks the user for input on the command line
+type Terminal struct {
+	out io.Writer
+}
+
+// NewTerminal creates a user interaction that reads from standard input and writes the questions to out
+func NewTerminal(out io.Writer) *Terminal {
+	return &Terminal{out: out}
+}
+
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("error reading user input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Prompt asks the user to type an answer. The input is hidden for passwords.
+func (t *Terminal) Prompt(message string, defaultValue string, password bool) (interface{}, error) {
+	fmt.Fprint(t.out, "? "+message+" ")
+	if defaultValue != "" && !password {
+		fmt.Fprintf(t.out, "(%s) ", defaultValue)
+	}
+
+	var answer string
+	if fd := int(os.Stdin.Fd()); password && term.IsTerminal(fd) {
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(t.out)
+		if err != nil {
+			return nil, fmt.Errorf("error reading password: %w", err)
+		}
+		answer = string(secret)
+	} else {
+		line, err := readLine()
+		if err != nil {
+			return nil, err
+		}
+		answer = line
+	}
+
+	if answer == "" {
+		answer = defaultValue
+	}
+	return answer, nil
+}
+
+// Select asks the user to select one or more items from a list, by number or by name. An empty answer selects the
+// default.
+func (t *Terminal) Select(message string, choices []commands.Choice, defaultValue interface{}, multiple bool) ([]commands.Choice, error) {
+	fmt.Fprintln(t.out, "? "+message)
+	for i, c := range choices {
+		fmt.Fprintf(t.out, "  %d) %s\n", i+1, c.Display)
+	}
+
+	hint := "Enter a number"
+	if multiple {
+		hint = "Enter numbers separated by commas"
+	}
+	var defaults []commands.Choice
+	if defaultValue != nil {
+		defaults, _ = findChoices(defaultValue, choices, multiple)
+	}
+	if defaults != nil {
+		shown := make([]string, len(defaults))
+		for i, c := range defaults {
+			shown[i] = c.Display
+		}
+		hint += " (" + strings.Join(shown, ", ") + ")"
+	}
+	for {
+		fmt.Fprint(t.out, "  "+hint+": ")
+		line, err := readLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" && defaults != nil {
+			return defaults, nil
+		}
+		if selected := parseSelection(line, choices, multiple); selected != nil {
+			return selected, nil
+		}
+		fmt.Fprintf(t.out, "  Invalid choice: %s\n", line)
+	}
+}
+
+// parseSelection returns the choices that were selected, or nil if the selection is not valid
+func parseSelection(line string, choices []commands.Choice, multiple bool) []commands.Choice {
+	parts := []string{line}
+	if multiple {
+		parts = strings.Split(line, ",")
+	}
+
+	var selected []commands.Choice
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		index := -1
+		if n, err := strconv.Atoi(part); err == nil && n >= 1 && n <= len(choices) {
+			index = n - 1
+		} else {
+			for i, c := range choices {
+				if c.Display == part {
+					index = i
+					break
+				}
+			}
+		}
+		if index < 0 {
+			return nil
+		}
+		selected = append(selected, choices[index])
+	}
+	return selected
+}
+
+// displayText returns the text that is shown for a value: strings as they are, other values as YAML
+func displayText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return values.Text(value)
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Base64Encode encodes text with the standard Base64 alphabet, with padding
+func Base64Encode(data interface{}) (string, error) {
+	text, err := valueText("Base64 encode", data)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(text)), nil
+}
+
+// Base64Decode decodes Base64 in the standard or the URL alphabet, with or without padding. Line breaks in the
+// encoded text are ignored. The decoded bytes are kept as they are, also if they are not valid UTF-8, so binary
+// content can be written to a file unchanged.
+func Base64Decode(data interface{}) (string, error) {
+	text, err := valueText("Base64 decode", data)
+	if err != nil {
+		return "", err
+	}
+	text = strings.Join(strings.Fields(text), "")
+
+	var decodeErr error
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		decoded, err := encoding.DecodeString(text)
+		if err == nil {
+			return string(decoded), nil
+		}
+		if decodeErr == nil {
+			decodeErr = err
+		}
+	}
+	return "", fmt.Errorf("Base64 decode: invalid Base64: %w", decodeErr)
+}
+
+// valueText gives the text of a value. Lists and objects are not accepted.
+func valueText(command string, data interface{}) (string, error) {
+	switch v := data.(type) {
+	case string:
+		return v, nil
+	case map[string]interface{}, []interface{}, nil:
+		return "", fmt.Errorf("%s expects a value, got: %v", command, data)
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
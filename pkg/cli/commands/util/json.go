@@ -0,0 +1,22 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// FormatJSON formats a value as JSON, on one line or indented with two spaces. Object keys are sorted, and
+// characters like '<' are kept as they are.
+func FormatJSON(value interface{}, indent bool) (string, error) {
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if indent {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(out.String(), "\n"), nil
+}
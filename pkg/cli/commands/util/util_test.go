@@ -0,0 +1,64 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFormatJSON(t *testing.T) {
+	value := map[string]interface{}{"b": []interface{}{1, "<x>"}, "a": nil}
+	compact, err := FormatJSON(value, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact != `{"a":null,"b":[1,"<x>"]}` {
+		t.Errorf("Unexpected JSON: %s", compact)
+	}
+	indented, err := FormatJSON(map[string]interface{}{"a": 1}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indented != "{\n  \"a\": 1\n}" {
+		t.Errorf("Unexpected JSON: %q", indented)
+	}
+}
+
+func TestBase64(t *testing.T) {
+	binary := string([]byte{0xff, 0x00, 0xfe, 'a'})
+	encoded, err := Base64Encode(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "/wD+YQ==" {
+		t.Errorf("Unexpected encoding: %s", encoded)
+	}
+	for _, text := range []string{encoded, "/wD+YQ", "_wD-YQ==", "/wD+\nYQ=="} {
+		decoded, err := Base64Decode(text)
+		if err != nil {
+			t.Errorf("%s: %v", text, err)
+		} else if decoded != binary {
+			t.Errorf("%s: the bytes changed, got %q", text, decoded)
+		}
+	}
+	if _, err := Base64Decode("not base64!"); err == nil {
+		t.Error("Expected an error for invalid Base64")
+	}
+	if _, err := Base64Encode(map[string]interface{}{}); err == nil {
+		t.Error("Expected an error for an object")
+	}
+}
+
+func TestWaitIsInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Wait(ctx, 60); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Wait to stop, got: %v", err)
+	}
+	if err := Wait(context.Background(), 0.01); err != nil {
+		t.Error(err)
+	}
+	if err := Wait(context.Background(), "soon"); err == nil {
+		t.Error("Expected an error for a value that is not a number")
+	}
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Wait waits for a number of seconds. It stops early with an error when the context is done, for example when the
+// user presses Ctrl-C or a test times out.
+func Wait(ctx context.Context, data interface{}) error {
+	var seconds float64
+	switch v := data.(type) {
+	case int:
+		seconds = float64(v)
+	case float64:
+		seconds = v
+	default:
+		return fmt.Errorf("Invalid value for 'Wait' command, expected a number of seconds, got: %v", data)
+	}
+	if seconds < 0 {
+		return fmt.Errorf("Invalid value for 'Wait' command, can not wait %v seconds", seconds)
+	}
+
+	timer := time.NewTimer(time.Duration(seconds * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("Wait was interrupted: %w", ctx.Err())
+	}
+}
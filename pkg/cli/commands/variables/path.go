@@ -0,0 +1,440 @@
+package variables
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"instacli/pkg/values"
+)
+
+// Path is a parsed path into a value, like '.items[0].name'. It has these segments:
+//
+//	.key            property of an object
+//	["first name"]  property with a name that is not a plain word
+//	[2], [-1]       item of a list, counted from the end if negative
+//	[1:3], [:-1]    part of a list
+//	.*, [*]         all items of a list or all values of an object
+//	[?age > 18]     items of a list that match a condition
+//
+// Slices, wildcards and filters give a list. The segments after them are applied to each item in that list, and
+// items that do not have the rest of the path are left out.
+type Path []pathSegment
+
+type segmentKind int
+
+const (
+	keySegment segmentKind = iota
+	indexSegment
+	sliceSegment
+	wildcardSegment
+	filterSegment
+)
+
+type pathSegment struct {
+	kind  segmentKind
+	key   string
+	index int
+	// start and end of a slice. They are nil if not given.
+	start, end *int
+	filter     *pathFilter
+}
+
+// pathFilter is the condition of a filter segment: a path in the item, optionally compared to a value
+type pathFilter struct {
+	path     Path
+	operator string
+	value    interface{}
+}
+
+// Filter operators. The first one in a condition is used, and the longest if more start at the same place.
+var filterOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// PathSyntaxError is returned for a path that can not be parsed
+type PathSyntaxError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathSyntaxError) Error() string {
+	return fmt.Sprintf("Invalid path '%s': %v", e.Path, e.Err)
+}
+
+func (e *PathSyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// ParsePath parses a path like '.items[0].name'. An empty path refers to the value itself.
+func ParsePath(path string) (Path, error) {
+	p := &pathParser{text: path}
+	segments, err := p.parse()
+	if err != nil {
+		var syntaxErr *PathSyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, err
+		}
+		return nil, &PathSyntaxError{Path: path, Err: err}
+	}
+	return segments, nil
+}
+
+type pathParser struct {
+	text string
+	pos  int
+}
+
+func (p *pathParser) parse() (Path, error) {
+	var segments Path
+	for p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case '.':
+			p.pos++
+			segment, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+		case '[':
+			p.pos++
+			segment, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+		default:
+			return nil, fmt.Errorf("expected '.' or '[' at '%s'", p.text[p.pos:])
+		}
+	}
+	return segments, nil
+}
+
+// parseKey parses the key after a dot, up to the next dot or bracket
+func (p *pathParser) parseKey() (pathSegment, error) {
+	end := strings.IndexAny(p.text[p.pos:], ".[]")
+	if end == -1 {
+		end = len(p.text) - p.pos
+	}
+	key := p.text[p.pos : p.pos+end]
+	p.pos += end
+	switch key {
+	case "":
+		return pathSegment{}, fmt.Errorf("missing key after '.'")
+	case "*":
+		return pathSegment{kind: wildcardSegment}, nil
+	}
+	return pathSegment{kind: keySegment, key: key}, nil
+}
+
+// parseBracket parses the part between brackets, after the opening bracket
+func (p *pathParser) parseBracket() (pathSegment, error) {
+	rest := p.text[p.pos:]
+	if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+		key, length, err := parseQuoted(rest)
+		if err != nil {
+			return pathSegment{}, err
+		}
+		p.pos += length
+		return pathSegment{kind: keySegment, key: key}, p.expect(']')
+	}
+
+	end := strings.IndexByte(rest, ']')
+	if end == -1 {
+		return pathSegment{}, fmt.Errorf("missing ']'")
+	}
+	content := strings.TrimSpace(rest[:end])
+	p.pos += end + 1
+
+	switch {
+	case content == "*":
+		return pathSegment{kind: wildcardSegment}, nil
+	case strings.HasPrefix(content, "?"):
+		filter, err := parseFilter(strings.TrimSpace(content[1:]))
+		if err != nil {
+			return pathSegment{}, err
+		}
+		return pathSegment{kind: filterSegment, filter: filter}, nil
+	case strings.Contains(content, ":"):
+		return parseSlice(content)
+	}
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return pathSegment{}, fmt.Errorf("'%s' is not an index, use quotes for a key", content)
+	}
+	return pathSegment{kind: indexSegment, index: index}, nil
+}
+
+func (p *pathParser) expect(c byte) error {
+	if p.pos >= len(p.text) || p.text[p.pos] != c {
+		return fmt.Errorf("missing '%c'", c)
+	}
+	p.pos++
+	return nil
+}
+
+// parseQuoted reads a quoted string at the start of text. It returns the string and the length it takes in text.
+func parseQuoted(text string) (string, int, error) {
+	quote := text[0]
+	var value strings.Builder
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if i+1 < len(text) {
+				i++
+				value.WriteByte(text[i])
+			}
+		case quote:
+			return value.String(), i + 1, nil
+		default:
+			value.WriteByte(text[i])
+		}
+	}
+	return "", 0, fmt.Errorf("missing closing quote in %s", text)
+}
+
+func parseSlice(content string) (pathSegment, error) {
+	from, to, _ := strings.Cut(content, ":")
+	segment := pathSegment{kind: sliceSegment}
+	for _, bound := range []struct {
+		text   string
+		target **int
+	}{{from, &segment.start}, {to, &segment.end}} {
+		text := strings.TrimSpace(bound.text)
+		if text == "" {
+			continue
+		}
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return pathSegment{}, fmt.Errorf("'%s' is not an index in slice [%s]", text, content)
+		}
+		*bound.target = &n
+	}
+	return segment, nil
+}
+
+// parseFilter parses a condition like 'age > 18', 'name == "Alice"' or 'active'
+func parseFilter(condition string) (*pathFilter, error) {
+	if condition == "" {
+		return nil, fmt.Errorf("missing condition after '?'")
+	}
+	left, operator, right := condition, "", ""
+	at := len(condition)
+	for _, op := range filterOperators {
+		if i := strings.Index(condition, op); i != -1 && i < at {
+			at = i
+			left, operator, right = condition[:i], op, condition[i+len(op):]
+		}
+	}
+
+	left = strings.TrimSpace(left)
+	if left == "@" {
+		left = ""
+	} else if !strings.HasPrefix(left, "[") && !strings.HasPrefix(left, "@") {
+		left = "." + left
+	}
+	path, err := ParsePath(strings.TrimPrefix(left, "@"))
+	if err != nil {
+		return nil, err
+	}
+	filter := &pathFilter{path: path, operator: operator}
+	if operator == "" {
+		return filter, nil
+	}
+
+	right = strings.TrimSpace(right)
+	if right == "" {
+		return nil, fmt.Errorf("missing value after '%s'", operator)
+	}
+	if right[0] == '"' || right[0] == '\'' {
+		value, length, err := parseQuoted(right)
+		if err != nil {
+			return nil, err
+		}
+		if length != len(right) {
+			return nil, fmt.Errorf("unexpected '%s' after value", right[length:])
+		}
+		filter.value = value
+		return filter, nil
+	}
+	filter.value = scalarValue(right)
+	return filter, nil
+}
+
+// scalarValue reads an unquoted value in a filter as a number, boolean or null if it is one, and as text otherwise
+func scalarValue(text string) interface{} {
+	if n, err := strconv.Atoi(text); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f
+	}
+	switch text {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	return text
+}
+
+// Get returns the value that the path refers to
+func (path Path) Get(val interface{}) (interface{}, error) {
+	for i, segment := range path {
+		switch segment.kind {
+		case keySegment:
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Cannot access key '%s' on %s", segment.key, kindOf(val))
+			}
+			if val, ok = m[segment.key]; !ok {
+				return nil, fmt.Errorf("Key '%s' not found", segment.key)
+			}
+		case indexSegment:
+			list, ok := val.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Cannot index %s", kindOf(val))
+			}
+			index := segment.index
+			if index < 0 {
+				index += len(list)
+			}
+			if index < 0 || index >= len(list) {
+				return nil, fmt.Errorf("Index %d out of range, the list has %d items", segment.index, len(list))
+			}
+			val = list[index]
+		default:
+			items, err := segment.selectItems(val)
+			if err != nil {
+				return nil, err
+			}
+			return path[i+1:].project(items), nil
+		}
+	}
+	return val, nil
+}
+
+// project applies the path to each item, leaving out the items that do not have it
+func (path Path) project(items []interface{}) []interface{} {
+	result := []interface{}{}
+	for _, item := range items {
+		if value, err := path.Get(item); err == nil {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// selectItems returns the items of a list, or the values of an object, that a slice, wildcard or filter selects
+func (segment pathSegment) selectItems(val interface{}) ([]interface{}, error) {
+	var items []interface{}
+	switch v := val.(type) {
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		if segment.kind == sliceSegment {
+			return nil, fmt.Errorf("Cannot slice an object")
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			items = append(items, v[key])
+		}
+	default:
+		return nil, fmt.Errorf("Cannot select items from %s", kindOf(val))
+	}
+
+	switch segment.kind {
+	case sliceSegment:
+		start, end := bound(segment.start, 0, len(items)), bound(segment.end, len(items), len(items))
+		if start >= end {
+			return []interface{}{}, nil
+		}
+		return items[start:end], nil
+	case filterSegment:
+		selected := []interface{}{}
+		for _, item := range items {
+			if segment.filter.matches(item) {
+				selected = append(selected, item)
+			}
+		}
+		return selected, nil
+	}
+	return items, nil
+}
+
+// bound returns the position in a list of length n that a slice bound refers to
+func bound(b *int, defaultValue int, n int) int {
+	if b == nil {
+		return defaultValue
+	}
+	i := *b
+	if i < 0 {
+		i += n
+	}
+	return min(max(i, 0), n)
+}
+
+// matches tells if an item passes the filter
+func (f *pathFilter) matches(item interface{}) bool {
+	value, err := f.path.Get(item)
+	if err != nil {
+		return false
+	}
+	if f.operator == "" {
+		return value != nil && value != false
+	}
+
+	if a, ok := values.Number(value); ok {
+		if b, ok := values.Number(f.value); ok {
+			return compare(f.operator, a-b)
+		}
+	}
+	if a, ok := value.(string); ok {
+		if b, ok := f.value.(string); ok {
+			return compare(f.operator, float64(strings.Compare(a, b)))
+		}
+	}
+	switch f.operator {
+	case "==":
+		return fmt.Sprint(value) == fmt.Sprint(f.value)
+	case "!=":
+		return fmt.Sprint(value) != fmt.Sprint(f.value)
+	}
+	return false
+}
+
+// compare applies the operator to the difference between two values
+func compare(operator string, diff float64) bool {
+	switch operator {
+	case "==":
+		return diff == 0
+	case "!=":
+		return diff != 0
+	case ">":
+		return diff > 0
+	case ">=":
+		return diff >= 0
+	case "<":
+		return diff < 0
+	default:
+		return diff <= 0
+	}
+}
+
+func kindOf(val interface{}) string {
+	switch val.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("'%v'", val)
+}
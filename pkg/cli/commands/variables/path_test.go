@@ -0,0 +1,78 @@
+package variables
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGetValue(t *testing.T) {
+	var data interface{}
+	source := `
+users:
+  - {name: Alice, age: 30, first name: Al}
+  - {name: Bob, age: 12}
+  - {name: Carol, age: 45, tags: [admin]}
+config:
+  "a.b": dotted
+  "first name": spaced
+`
+	if err := yaml.Unmarshal([]byte(source), &data); err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]interface{}{"x": data}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{"x.users[0].name", "Alice"},
+		{"x.users[-1].name", "Carol"},
+		{`x.config["a.b"]`, "dotted"},
+		{`x.config['first name']`, "spaced"},
+		{"x.users[1:].name", []interface{}{"Bob", "Carol"}},
+		{"x.users[:-2].name", []interface{}{"Alice"}},
+		{"x.users[*].age", []interface{}{30, 12, 45}},
+		{"x.users.*.name", []interface{}{"Alice", "Bob", "Carol"}},
+		{"x.users[?age > 18].name", []interface{}{"Alice", "Carol"}},
+		{`x.users[?name == "Bob"].age`, []interface{}{12}},
+		{"x.users[?tags].name", []interface{}{"Carol"}},
+		{`x.users[*]["first name"]`, []interface{}{"Al"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			actual, err := GetValue(tt.path, vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Not equal:\n  Expected: %v\n  Actual:   %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestInvalidPath(t *testing.T) {
+	vars := map[string]interface{}{"x": []interface{}{"a"}}
+	tests := map[string]string{
+		"x[abc]":    "'abc' is not an index",
+		"x[0":       "missing ']'",
+		`x["a]`:     "missing closing quote",
+		"x..a":      "missing key",
+		"x[?]":      "missing condition",
+		"x[?a == ]": "missing value",
+		"x[5]":      "out of range",
+	}
+	for path, message := range tests {
+		_, err := GetValue(path, vars)
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%s: expected error containing %q, got: %v", path, message, err)
+		}
+	}
+
+	if _, err := ResolveVariablesRecursive("${x[abc]}", vars); err == nil {
+		t.Error("Expected an error for an invalid path in a variable")
+	}
+}
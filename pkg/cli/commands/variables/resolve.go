@@ -1,16 +1,17 @@
 package variables
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
-	"strings"
 
-	"gopkg.in/yaml.v3"
+	"instacli/pkg/values"
 )
 
 var variableRegex = regexp.MustCompile(`\$\{([^}]+)}`)
 
-// ResolveVariablesInText replaces ${var} and ${var.path} in a string using the provided variable map.
+// ResolveVariablesInText replaces ${var} and ${var.path} in a string using the provided variable map. Values are
+// written in their canonical text.
 func ResolveVariablesInText(raw string, vars map[string]interface{}) (string, error) {
 	hadErr := false
 	var firstErr error
@@ -24,18 +25,8 @@ func ResolveVariablesInText(raw string, vars map[string]interface{}) (string, er
 			}
 			return match // leave the variable as-is
 		}
-		// If value is a slice or map, marshal to YAML block style
-		switch v := val.(type) {
-		case []interface{}, map[string]interface{}:
-			yamlBytes, err := yaml.Marshal(v)
-			if err != nil {
-				return fmt.Sprintf("%v", v)
-			}
-			// Remove trailing newline for inline use
-			return strings.TrimRight(string(yamlBytes), "\n")
-		default:
-			return fmt.Sprintf("%v", v)
-		}
+		// Lists and objects are written in YAML block style
+		return values.Text(val)
 	})
 	if hadErr {
 		return replaced, firstErr
@@ -53,7 +44,11 @@ func GetValue(varName string, vars map[string]interface{}) (interface{}, error)
 	if path == "" {
 		return val, nil
 	}
-	return resolvePath(val, path)
+	parsed, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Get(val)
 }
 
 // splitIntoVariableAndPath splits 'var.path[0].foo' into ('var', '.path[0].foo')
@@ -66,54 +61,15 @@ func splitIntoVariableAndPath(varName string) (string, string) {
 	return varName, ""
 }
 
-// resolvePath navigates through maps/slices according to a path like '.foo[0].bar'
-func resolvePath(val interface{}, path string) (interface{}, error) {
-	p := path
-	for len(p) > 0 {
-		if p[0] == '.' {
-			// Object key
-			p = p[1:]
-			end := strings.IndexAny(p, ".[[]")
-			if end == -1 {
-				end = len(p)
-			}
-			key := p[:end]
-			m, ok := val.(map[string]interface{})
-			if !ok {
-				return nil, fmt.Errorf("Cannot access key '%s' on non-object", key)
-			}
-			val, ok = m[key]
-			if !ok {
-				return nil, fmt.Errorf("Key '%s' not found", key)
-			}
-			p = p[end:]
-		} else if p[0] == '[' {
-			// Array index
-			end := strings.Index(p, "]")
-			if end == -1 {
-				return nil, fmt.Errorf("Unmatched '[' in path")
-			}
-			idxStr := p[1:end]
-			var idx int
-			fmt.Sscanf(idxStr, "%d", &idx)
-			slice, ok := val.([]interface{})
-			if !ok {
-				return nil, fmt.Errorf("Cannot index non-array")
-			}
-			if idx < 0 || idx >= len(slice) {
-				return nil, fmt.Errorf("Index %d out of range", idx)
-			}
-			val = slice[idx]
-			p = p[end+1:]
-		} else {
-			return nil, fmt.Errorf("Invalid path syntax: %s", p)
-		}
-	}
-	return val, nil
-}
-
 // ResolveVariablesRecursive recursively resolves variables in any value (string, map, slice, etc).
+// A value that is only a reference to an unknown variable is kept as it is.
 func ResolveVariablesRecursive(val interface{}, vars map[string]interface{}) (interface{}, error) {
+	return ResolveVariables(val, vars, false)
+}
+
+// ResolveVariables resolves variables in any value like ResolveVariablesRecursive. In strict mode, a reference to an
+// unknown variable is always an error.
+func ResolveVariables(val interface{}, vars map[string]interface{}, strict bool) (interface{}, error) {
 	// Handle nil
 	if val == nil {
 		return nil, nil
@@ -124,13 +80,17 @@ func ResolveVariablesRecursive(val interface{}, vars map[string]interface{}) (in
 		// If the string is exactly a variable reference, return the value as-is (recursively resolve if needed)
 		if matches := variableRegex.FindStringSubmatch(v); matches != nil && v == matches[0] {
 			resolved, err := GetValue(matches[1], vars)
+			var syntaxErr *PathSyntaxError
+			if errors.As(err, &syntaxErr) || err != nil && strict {
+				return nil, err
+			}
 			if err != nil {
 				// If not found, return the original string
 				return v, nil
 			}
 			// If the resolved value is a string that is itself a variable reference, resolve recursively
 			if strVal, ok := resolved.(string); ok {
-				return ResolveVariablesRecursive(strVal, vars)
+				return ResolveVariables(strVal, vars, strict)
 			}
 			return resolved, nil
 		}
@@ -138,7 +98,7 @@ func ResolveVariablesRecursive(val interface{}, vars map[string]interface{}) (in
 	case []interface{}:
 		result := make([]interface{}, len(v))
 		for i, elem := range v {
-			resolved, err := ResolveVariablesRecursive(elem, vars)
+			resolved, err := ResolveVariables(elem, vars, strict)
 			if err != nil {
 				return nil, err
 			}
@@ -148,12 +108,13 @@ func ResolveVariablesRecursive(val interface{}, vars map[string]interface{}) (in
 	case map[string]interface{}:
 		result := make(map[string]interface{}, len(v))
 		for key, elem := range v {
-			resolved, err := ResolveVariablesRecursive(elem, vars)
+			resolved, err := ResolveVariables(elem, vars, strict)
 			if err != nil {
 				return nil, err
 			}
 			result[key] = resolved
 		}
+		values.SetKeyOrder(result, values.Keys(v))
 		return result, nil
 	default:
 		// For other types (numbers, bools, etc), return as-is
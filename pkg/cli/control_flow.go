@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/commands/variables"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loopVariableRegex matches the field of For each that declares the loop variable, like '${name} in'
+var loopVariableRegex = regexp.MustCompile(`^\$\{([^}]+)} in$`)
+
+// defaultLoopVariable is the loop variable of For each if it does not declare one
+const defaultLoopVariable = "item"
+
+// handleDo runs the commands in the data. They share the variables of the script, so variables that are set in Do
+// are known after it. The output of the last command is the output of Do.
+func handleDo(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+	node, err := commandNode(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	cmds, err := ParseCommands(node)
+	if err != nil {
+		return nil, err
+	}
+	if err := runCommands(ctx, cmds); err != nil {
+		return nil, err
+	}
+	return ctx.GetOutput(), nil
+}
+
+// handleForEach runs the commands in the data for each item in a list, or for each entry in an object. The loop
+// variable is declared by the block: it is only known inside the loop. Other variables that are set in the loop are
+// script variables. The output is the list of the outputs of each iteration, or an object with the output of each
+// entry.
+func handleForEach(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+	node, err := commandNode(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	cmds, err := ParseCommands(node)
+	if err != nil {
+		return nil, err
+	}
+
+	loopVariable, items := defaultLoopVariable, ctx.GetOutput()
+	var itemsNode *yaml.Node
+	var body []commands.Command
+	for i, cmd := range cmds {
+		if match := loopVariableRegex.FindStringSubmatch(cmd.Name); match != nil && i == 0 {
+			loopVariable, itemsNode = match[1], cmd.Node
+			if items, err = variables.ResolveVariables(cmd.Data, ctx.Vars(), ctx.Strict()); err != nil {
+				return nil, fmt.Errorf("line %d: error resolving variables in %s: %w", cmd.Line, cmd.Name, err)
+			}
+			continue
+		}
+		body = append(body, cmd)
+	}
+
+	switch v := items.(type) {
+	case []interface{}:
+		outputs := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			output, err := runIteration(ctx, loopVariable, item, body)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, output)
+		}
+		return outputs, nil
+	case map[string]interface{}:
+		outputs := make(map[string]interface{}, len(v))
+		for _, key := range commands.ObjectKeys(itemsNode, v) {
+			entry := map[string]interface{}{"key": key, "value": v[key]}
+			output, err := runIteration(ctx, loopVariable, entry, body)
+			if err != nil {
+				return nil, err
+			}
+			outputs[key] = output
+		}
+		return outputs, nil
+	case nil:
+		return nil, fmt.Errorf("For each: nothing to loop over")
+	default:
+		return nil, fmt.Errorf("For each: can not loop over %v", items)
+	}
+}
+
+// runIteration runs the commands of For each for one item, in a block that declares the loop variable
+func runIteration(ctx *commands.ExecutionContext, loopVariable string, item interface{}, body []commands.Command) (interface{}, error) {
+	ctx.PushScope(map[string]interface{}{loopVariable: item})
+	defer ctx.PopScope()
+	if err := runCommands(ctx, body); err != nil {
+		return nil, err
+	}
+	return ctx.GetOutput(), nil
+}
+
+// commandNode returns the YAML source of the data of the running command, so commands in it keep their order
+func commandNode(ctx *commands.ExecutionContext, data interface{}) (*yaml.Node, error) {
+	if node := ctx.CommandNode(); node != nil {
+		return node, nil
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(data); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVariableScopes(t *testing.T) {
+	script, err := ParseScript([]byte(`
+${name}: outer
+${count}: 0
+
+For each:
+  ${name} in: [a, b]
+  ${count}: ${name}
+  ${last}: ${name}
+
+Assert equals:
+  - actual: ${name}
+    expected: outer
+  - actual: ${count}
+    expected: b
+  - actual: ${last}
+    expected: b
+
+Do:
+  ${inside}: set in Do
+
+Assert equals:
+  actual: ${inside}
+  expected: set in Do
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executeScript(script, nil, true, &strings.Builder{}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	source := []byte("For each:\n  ${item} in: [a]\n  Output: ${item}\n\n---\nPrint: ${item}\n")
+
+	script, err := ParseScript(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := executeScript(script, nil, true, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "${item}\n" {
+		t.Errorf("Expected the unknown variable to be kept, got: %q", out.String())
+	}
+
+	script.Strict = true
+	err = executeScript(script, nil, true, &strings.Builder{}, nil)
+	if err == nil || err.Error() != "line 6: error resolving variables in Print: Unknown variable ${item}" {
+		t.Errorf("Expected an error naming the variable and line, got: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefineCommand is the command that adds a command of its own to the script
+const DefineCommand = "Define command"
+
+// CommandDefinition is the data of the Define command command
+type CommandDefinition struct {
+	Name string `yaml:"name"`
+	// InputType is the type of the data the command is called with. Data that does not match it is rejected.
+	InputType *types.Type `yaml:"input type"`
+	// OutputType is the type of the output of the command
+	OutputType *types.Type `yaml:"output type"`
+	// Do holds the commands that run when the command is called
+	Do yaml.Node `yaml:"do"`
+}
+
+// handleDefineCommand adds a command to the commands of the script. It can be called by name in the rest of the
+// script, with its data as the input.
+func handleDefineCommand(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+	node, err := commandNode(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	def, err := ParseCommandDefinition(node)
+	if err != nil {
+		return nil, err
+	}
+	source := &ParsedScript{File: ctx.ScriptFile(), Dir: ctx.ScriptDir(), Types: ctx.Types()}
+	_, err = def.Register(ctx.Commands(), source)
+	return nil, err
+}
+
+// ParseCommandDefinition reads the data of a Define command command
+func ParseCommandDefinition(node *yaml.Node) (*CommandDefinition, error) {
+	def := &CommandDefinition{}
+	if err := node.Decode(def); err != nil {
+		return nil, fmt.Errorf("error parsing command definition: %w", err)
+	}
+	if def.Name == "" {
+		return nil, fmt.Errorf("%s: missing 'name'", DefineCommand)
+	}
+	if def.Do.Kind == 0 {
+		return nil, fmt.Errorf("%s: missing 'do' in '%s'", DefineCommand, def.Name)
+	}
+	return def, nil
+}
+
+// Register adds the command to the registry. Its types are those of the script that defines it, and its commands
+// run in the directory of that script.
+func (d *CommandDefinition) Register(registry *commands.Registry, source *ParsedScript) (*commands.Definition, error) {
+	if existing := registry.Get(d.Name); existing != nil && existing.Builtin {
+		return nil, fmt.Errorf("%s: '%s' is a built-in command", DefineCommand, d.Name)
+	}
+	body, err := ParseCommands(&d.Do)
+	if err != nil {
+		return nil, err
+	}
+
+	command := &commands.Definition{
+		Name: d.Name,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return d.run(ctx, body, source, data)
+		},
+	}
+	if d.InputType != nil {
+		input, err := source.Types.Resolve(d.InputType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: input type of '%s': %w", DefineCommand, d.Name, err)
+		}
+		command.HandlesLists = input.Base == types.Array
+		command.Validate = func(data interface{}) ([]string, error) {
+			return typeProblems(source.Types.Check(data, d.InputType))
+		}
+	}
+	registry.Register(command)
+	return command, nil
+}
+
+// run runs the commands of the defined command with variables of their own. The data is the input, and the output
+// of the commands is the output of the command.
+func (d *CommandDefinition) run(ctx *commands.ExecutionContext, body []commands.Command, source *ParsedScript,
+	data interface{}) (interface{}, error) {
+	if err := checkRecursion(ctx, source.File, nil); err != nil {
+		return nil, err
+	}
+	child := ctx.NewChild(source.File, nil)
+	child.SetScriptDir(source.Dir)
+	child.SetTypes(source.Types)
+	child.SetVar(InputVariable, data)
+	if err := runCommands(child, body); err != nil {
+		return nil, err
+	}
+
+	output := child.GetOutput()
+	if d.OutputType != nil && output != nil {
+		problems, err := typeProblems(source.Types.Check(output, d.OutputType))
+		if err != nil {
+			return nil, err
+		}
+		if len(problems) > 0 {
+			return nil, fmt.Errorf("Output of '%s' does not match its output type:\n  %s", d.Name,
+				strings.Join(problems, "\n  "))
+		}
+	}
+	return output, nil
+}
+
+// importedCommand looks for a command with the given name that is defined in one of the scripts that the
+// directory imports. The command is added to the registry if it is found, and nil is returned otherwise.
+func importedCommand(registry *commands.Registry, dir string, name string) (*commands.Definition, error) {
+	info, err := LoadDirectoryInfo(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, imported := range info.Imports {
+		script, err := LoadScript(filepath.Join(dir, imported))
+		if err != nil {
+			return nil, err
+		}
+		for _, cmd := range script.Commands {
+			if cmd.Name != DefineCommand {
+				continue
+			}
+			def, err := ParseCommandDefinition(cmd.Node)
+			if err != nil {
+				return nil, fmt.Errorf("%s line %d: %w", imported, cmd.Line, err)
+			}
+			if def.Name == name {
+				return def.Register(registry, script)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// typeProblems returns the violations of a type check as problems of the command data
+func typeProblems(err error) ([]string, error) {
+	var validationErr *types.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, err
+	}
+	problems := make([]string, len(validationErr.Violations))
+	for i, v := range validationErr.Violations {
+		problems[i] = v.String()
+	}
+	return problems, nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefineCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"types.yaml": "FullName:\n  base: object\n  properties:\n    first_name: {type: string}\n" +
+			"    last_name: {type: string}\n",
+		"main.cli": "${name}: Outer\n\n---\n" +
+			"Define command:\n  name: Get full name\n  input type: FullName\n  output type: string\n" +
+			"  do:\n    ${name}: Inner\n    Output: ${input.first_name} ${input.last_name}\n\n---\n" +
+			"Get full name:\n  first_name: Alice\n  last_name: Bob\n\n---\n" +
+			"Expected output: Alice Bob\n\n---\n" +
+			"Assert equals:\n  actual: ${name}\n  expected: Outer\n\n---\n" +
+			"Shout: Hello\n\n---\n" +
+			"Expected output: HELLO!\n\n---\n" +
+			"Get full name:\n  first_name: Alice\n",
+		".instacli.yaml":   "imports:\n  - lib/commands.cli\n",
+		"lib/commands.cli": "Define command:\n  name: Shout\n  do:\n    Output: HELLO!\n",
+	})
+
+	script := NewScript(filepath.Join(dir, "main.cli"), false, false, false, true)
+	script.Out = &strings.Builder{}
+	err := script.Execute()
+	if err == nil || !strings.Contains(err.Error(), "Invalid command format for 'Get full name'") {
+		t.Errorf("Expected invalid input for the last command, got: %v", err)
+	}
+}
+
+func TestDefineBuiltinCommand(t *testing.T) {
+	for _, name := range []string{"Json", "Define command", "Print"} {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"main.cli": "Define command:\n  name: " + name + "\n  do:\n    Output: replaced\n",
+		})
+		script := NewScript(filepath.Join(dir, "main.cli"), false, false, false, true)
+		script.Out = &strings.Builder{}
+		if err := script.Execute(); err == nil || !strings.Contains(err.Error(), "is a built-in command") {
+			t.Errorf("Expected '%s' to be protected, got: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReadmeFile describes the scripts in a directory
+const ReadmeFile = "README.md"
+
+// CommandInfo describes a command of a directory: a script, or a subdirectory with scripts
+type CommandInfo struct {
+	// Name is how the command is given on the command line, for example 'create-greeting'
+	Name        string
+	Description string
+	Hidden      bool
+	// Path is the script file or the subdirectory
+	Path string
+}
+
+// CliCommandName returns the name of a script or directory on the command line: the file name without extension,
+// in lower case and with dashes for spaces. For example, 'Create greeting.cli' is called with 'create-greeting'.
+func CliCommandName(fileName string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(fileName, MarkdownScriptExtension), ScriptExtension)
+	return strings.ToLower(strings.ReplaceAll(name, " ", "-"))
+}
+
+// DirectoryDescription returns the description of a directory: the first line of text in its README.md, or the
+// Script info in its .instacli.yaml file.
+func DirectoryDescription(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ReadmeFile))
+	if err == nil {
+		return MarkdownDescription(ScanMarkdown(data)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	info, err := LoadDirectoryInfo(dir)
+	if err != nil {
+		return "", err
+	}
+	return info.ScriptInfo.Description, nil
+}
+
+// DirectoryCommands returns the commands of a directory, sorted by name: its scripts, and the subdirectories that
+// have scripts, except the 'tests' directory
+func DirectoryCommands(dir string) ([]*CommandInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var commands []*CommandInfo
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		var command *CommandInfo
+		switch {
+		case entry.IsDir() && entry.Name() != "tests" && hasScripts(path):
+			command, err = directoryCommand(path)
+		case !entry.IsDir() && isScriptFile(entry.Name()):
+			command, err = scriptFileCommand(path)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return commands, nil
+}
+
+func directoryCommand(dir string) (*CommandInfo, error) {
+	info, err := LoadDirectoryInfo(dir)
+	if err != nil {
+		return nil, err
+	}
+	description, err := DirectoryDescription(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &CommandInfo{
+		Name:        CliCommandName(filepath.Base(dir)),
+		Description: description,
+		Hidden:      info.Hidden || info.ScriptInfo.Hidden,
+		Path:        dir,
+	}, nil
+}
+
+// scriptFileCommand describes a script with the first line of text of a Markdown script, or its Script info
+func scriptFileCommand(file string) (*CommandInfo, error) {
+	script, err := LoadScript(file)
+	if err != nil {
+		return nil, err
+	}
+	description := script.Metadata.Description
+	if strings.HasSuffix(file, ".md") {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if text := MarkdownDescription(ScanMarkdown(data)); text != "" {
+			description = text
+		}
+	}
+	if description == "" {
+		description = ScriptCommandName(filepath.Base(file))
+	}
+	return &CommandInfo{
+		Name:        CliCommandName(filepath.Base(file)),
+		Description: description,
+		Hidden:      script.Metadata.Hidden,
+		Path:        file,
+	}, nil
+}
+
+// hasScripts tells if there is a script in a directory or one of its subdirectories
+func hasScripts(dir string) bool {
+	found := false
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && isScriptFile(entry.Name()) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// DirectoryHelp describes a directory and lists the commands in it that are not hidden
+func DirectoryHelp(dir string) (string, error) {
+	description, err := DirectoryDescription(dir)
+	if err != nil {
+		return "", err
+	}
+	commands, err := DirectoryCommands(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var help strings.Builder
+	if description = strings.TrimSpace(description); description != "" {
+		help.WriteString(description + "\n\n")
+	}
+	var visible []*CommandInfo
+	width := 0
+	for _, command := range commands {
+		if !command.Hidden {
+			visible = append(visible, command)
+			width = max(width, len(command.Name))
+		}
+	}
+	if len(visible) == 0 {
+		help.WriteString("No commands available.\n")
+		return help.String(), nil
+	}
+	help.WriteString("Available commands:\n")
+	for _, command := range visible {
+		help.WriteString(fmt.Sprintf("  %-*s   %s\n", width, command.Name, strings.TrimSpace(command.Description)))
+	}
+	return help.String(), nil
+}
+
+// FindDirectoryCommand follows the commands given after a directory on the command line to the script or
+// subdirectory they name. It returns the path and the arguments that are left. Arguments are options from the
+// first one that starts with '-'.
+func FindDirectoryCommand(path string, args []string) (string, []string, error) {
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			break
+		}
+		commands, err := DirectoryCommands(path)
+		if err != nil {
+			return "", nil, err
+		}
+		var found *CommandInfo
+		for _, command := range commands {
+			if command.Name == CliCommandName(args[0]) {
+				found = command
+			}
+		}
+		if found == nil {
+			return "", nil, fmt.Errorf("Command '%s' not found in %s", args[0], filepath.Base(path))
+		}
+		path, args = found.Path, args[1:]
+	}
+	return path, args, nil
+}
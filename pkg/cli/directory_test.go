@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDirectoryHelp(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".instacli.yaml":          "Script info: Example scripts\n",
+		"create-greeting.cli":     "Script info: Creates a greeting\n\nOutput: Hello\n",
+		"helper.cli":              "Script info:\n  description: Helper\n  hidden: true\n",
+		"prompt.cli.md":           "# Prompt\n\nSimple prompt\n\n```yaml instacli\nPrompt: Name?\n```\n",
+		"more/README.md":          "# More\n\nMore scripts\n",
+		"more/greet.cli":          "Print: Hello\n",
+		"tests/greeting-test.cli": "Test case: Greeting\n",
+	})
+
+	help, err := DirectoryHelp(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Example scripts\n\n" +
+		"Available commands:\n" +
+		"  create-greeting   Creates a greeting\n" +
+		"  more              More scripts\n" +
+		"  prompt            Simple prompt\n"
+	if help != expected {
+		t.Errorf("Expected:\n%s\nActual:\n%s", expected, help)
+	}
+
+	path, args, err := FindDirectoryCommand(dir, []string{"more", "greet", "--name", "Alice"})
+	if err != nil || path != filepath.Join(dir, "more", "greet.cli") || len(args) != 2 {
+		t.Errorf("Expected more/greet.cli with the options, got: %s %v %v", path, args, err)
+	}
+	if _, _, err := FindDirectoryCommand(dir, []string{"missing"}); err == nil {
+		t.Error("Expected an error for an unknown command")
+	}
+}
@@ -1,62 +1,193 @@
 package cli
 
 import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
 	"instacli/pkg/spec"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
-// runSpecFile runs all test cases in a given Instacli spec file.
-func runSpecFile(t *testing.T, relPath string) {
-	specRoot := os.Getenv("INSTACLI_SPEC")
-	if specRoot == "" {
-		t.Fatal("INSTACLI_SPEC environment variable not set")
+// knownFailuresFile lists the spec test cases that the Go port does not pass yet, one per line
+const knownFailuresFile = "testdata/known-failures.txt"
+
+var updateKnownFailures = flag.Bool("update-known-failures", false, "rewrite "+knownFailuresFile+" with the spec test cases that fail")
+
+// TestInstacliSpec runs the test cases in the tests directories of the embedded spec and the code examples
+// in its Markdown documents. Failures are expected for the test cases in the known failures list only.
+func TestInstacliSpec(t *testing.T) {
+	knownFailures, err := readKnownFailures()
+	if err != nil {
+		t.Fatalf("Failed to read known failures: %v", err)
+	}
+	knownFailuresPath, err := filepath.Abs(knownFailuresFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Scripts refer to files next to them, and to files in the spec from the directory it is in, so the spec is
+	// run from disk
+	dir := t.TempDir()
+	root := filepath.Join(dir, "instacli-spec")
+	if err := os.CopyFS(root, spec.FS()); err != nil {
+		t.Fatalf("Failed to copy spec: %v", err)
+	}
+	// Examples run the samples from the directory the spec is in, like the cli is run from the project root
+	samples := os.DirFS(filepath.Join("..", "spec", "instacli", "samples"))
+	if err := os.CopyFS(filepath.Join(dir, "samples"), samples); err != nil {
+		t.Fatalf("Failed to copy samples: %v", err)
 	}
-	filename := relPath
-	data, err := spec.GetSpecFile(filename)
+	t.Chdir(dir)
+
+	files, err := FindTestFiles(root)
 	if err != nil {
-		t.Fatalf("Failed to read test file: %v", err)
+		t.Fatalf("Failed to find spec files: %v", err)
 	}
 
-	// Split on '---' to get each test case as a full YAML document
-	sections := strings.Split(string(data), "---")
-	for _, section := range sections {
-		section = strings.TrimSpace(section)
-		if section == "" {
+	// Commands that wait stop when the test times out, so the failure is reported instead of a hanging test
+	ctx := context.Background()
+	if deadline, ok := t.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-time.Second))
+		defer cancel()
+	}
+
+	seen := map[string]bool{}
+	var failures []string
+	total := 0
+	for _, file := range files {
+		if !isSpecTestFile(file) {
 			continue
 		}
-		// Use the first non-empty line as the test name if it starts with 'Test case:'
-		lines := strings.SplitN(section, "\n", 2)
-		name := "(unnamed)"
-		if len(lines) > 0 && strings.HasPrefix(lines[0], "Test case:") {
-			name = strings.TrimSpace(strings.TrimPrefix(lines[0], "Test case:"))
-		}
-		t.Run(name, func(t *testing.T) {
-			// Parse the entire section as a YAML script
-			script, err := ParseScript([]byte(section))
-			if err != nil {
-				t.Fatalf("ParseScript error: %v", err)
+		relPath, _ := filepath.Rel(root, file)
+		relPath = filepath.ToSlash(relPath)
+
+		testCases, err := LoadTestCases(file)
+		if err != nil {
+			testCases = nil
+			total++
+			failures = append(failures, relPath)
+			checkSpecResult(t, relPath, err, knownFailures)
+		}
+		for _, testCase := range testCases {
+			id := relPath + " > " + testCase.Name
+			for i := 2; seen[id]; i++ {
+				id = fmt.Sprintf("%s > %s (%d)", relPath, testCase.Name, i)
 			}
-			if err := ExecuteScript(script, nil); err != nil {
-				t.Errorf("Script execution error: %v", err)
+			seen[id] = true
+
+			total++
+			testCase.Script.Context = ctx
+			result := RunTestCase(testCase)
+			if !result.Passed() {
+				failures = append(failures, id)
 			}
-		})
+			checkSpecResult(t, id, result.Err, knownFailures)
+		}
+		RemoveTempDirs(testCases)
+	}
+
+	passed := total - len(failures)
+	t.Logf("Conformance: %d of %d (%.1f%%)", passed, total, 100*float64(passed)/float64(max(total, 1)))
+
+	if *updateKnownFailures {
+		if err := writeKnownFailures(knownFailuresPath, failures); err != nil {
+			t.Fatalf("Failed to write known failures: %v", err)
+		}
+	}
+}
+
+// TestScratchpadStore runs the Store examples in the scratchpad of the spec, which is not embedded. There is no Shell
+// command to clear the databases, so the examples run in an empty directory without it.
+func TestScratchpadStore(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "spec", "instacli", "instacli-spec", "scratchpad", "Store data with schema.cli"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "Shell:") {
+			lines = append(lines, line)
+		}
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"store.cli": strings.Join(lines, "\n")})
+	if err := os.Mkdir(filepath.Join(dir, "out"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	testCases, err := LoadTestCases(filepath.Join(dir, "store.cli"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(testCases) != 2 {
+		t.Fatalf("Expected 2 examples, got %d", len(testCases))
+	}
+	for _, testCase := range testCases {
+		if result := RunTestCase(testCase); !result.Passed() {
+			t.Errorf("%s: %v\n%s", testCase.Name, result.Err, result.Output)
+		}
+	}
+}
+
+// isSpecTestFile tells if a file is a script in a tests directory or a Markdown document
+func isSpecTestFile(file string) bool {
+	if strings.HasSuffix(file, MarkdownExtension) {
+		return true
+	}
+	return strings.HasSuffix(file, ScriptExtension) && filepath.Base(filepath.Dir(file)) == "tests"
+}
+
+func checkSpecResult(t *testing.T, id string, err error, knownFailures map[string]bool) {
+	t.Run(id, func(t *testing.T) {
+		switch {
+		case *updateKnownFailures:
+		case err != nil && !knownFailures[id]:
+			t.Errorf("%v", err)
+		case err == nil && knownFailures[id]:
+			t.Errorf("Passes now, remove it from %s", knownFailuresFile)
+		}
+	})
+}
+
+func readKnownFailures() (map[string]bool, error) {
+	file, err := os.Open(knownFailuresFile)
+	if os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	knownFailures := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			knownFailures[line] = true
+		}
 	}
+	return knownFailures, scanner.Err()
 }
 
-func TestInstacliSpecFiles(t *testing.T) {
-	specFiles := []string{
-		// Add more spec files here as needed, relative to $INSTACLI_SPEC
-		"commands/instacli/variables/tests/Output variable tests.cli",
-		"commands/instacli/variables/tests/Assignment tests.cli",
-		"commands/instacli/variables/tests/Variable replacement tests.cli",
-		// e.g. "commands/instacli/variables/tests/Other variable tests.cli",
-		// e.g. "commands/instacli/db/tests/Some db tests.cli",
+func writeKnownFailures(path string, failures []string) error {
+	sort.Strings(failures)
+	content := "# Spec test cases that the Go port does not pass yet. Regenerate with:\n" +
+		"#   go test ./pkg/cli -run TestInstacliSpec$ -update-known-failures\n"
+	for _, failure := range failures {
+		content += failure + "\n"
 	}
-	for _, relPath := range specFiles {
-		t.Run(relPath, func(t *testing.T) {
-			runSpecFile(t, relPath)
-		})
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
 	}
+	return os.WriteFile(path, []byte(content), 0o644)
 }
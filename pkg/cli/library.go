@@ -0,0 +1,419 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/commands/db"
+	"instacli/pkg/cli/commands/files"
+	"instacli/pkg/cli/commands/regex"
+	"instacli/pkg/cli/commands/schema"
+	"instacli/pkg/cli/commands/testing"
+	"instacli/pkg/cli/commands/userinteraction"
+	"instacli/pkg/cli/commands/util"
+	"instacli/pkg/cli/commands/variables"
+	"instacli/pkg/values"
+)
+
+// NewCommandLibrary creates a registry with all built-in commands
+func NewCommandLibrary() *commands.Registry {
+	library := commands.NewRegistry()
+
+	// Script definition
+	library.Register(&commands.Definition{
+		Name:            ScriptInfoCommand,
+		Namespace:       "instacli/script-info",
+		HandlesLists:    true,
+		DelayedResolver: true,
+		Handler:         handleScriptInfo,
+	})
+	library.Register(&commands.Definition{
+		Name:            DefineCommand,
+		DelayedResolver: true,
+		Handler:         handleDefineCommand,
+	})
+
+	// Control flow
+	library.Register(&commands.Definition{
+		Name:            "Do",
+		Namespace:       "instacli/control-flow",
+		DelayedResolver: true,
+		Handler:         handleDo,
+	})
+	library.Register(&commands.Definition{
+		Name:            "For each",
+		Namespace:       "instacli/control-flow",
+		DelayedResolver: true,
+		Handler:         handleForEach,
+	})
+
+	// Variables
+	library.Register(&commands.Definition{
+		Name:            "As",
+		Namespace:       "instacli/variables",
+		DelayedResolver: true,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			varName, _ := data.(string)
+			asCmd, err := variables.NewAsCommand(varName)
+			if err != nil {
+				return nil, err
+			}
+			return nil, asCmd.Execute(ctx)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:         "Output",
+		Namespace:    "instacli/variables",
+		HandlesLists: true,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return data, variables.NewOutputCommand(data).Execute(ctx)
+		},
+	})
+
+	// Testing
+	library.Register(&commands.Definition{
+		Name:      "Test case",
+		Namespace: "instacli/testing",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return nil, testing.NewTestCaseCommand().Execute(ctx)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:      "Code example",
+		Namespace: "instacli/testing",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return nil, testing.NewCodeExampleCommand().Execute(ctx)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:         "Assert equals",
+		Namespace:    "instacli/testing",
+		PipeProperty: "actual",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("Assert equals", data)
+			if err != nil {
+				return nil, err
+			}
+			assertCmd, err := testing.NewAssertEquals(m)
+			if err != nil {
+				return nil, err
+			}
+			// Trim whitespace for actual and expected if they are strings
+			if a, ok := assertCmd.Actual.(string); ok {
+				assertCmd.Actual = strings.TrimSpace(a)
+			}
+			if e, ok := assertCmd.Expected.(string); ok {
+				assertCmd.Expected = strings.TrimSpace(e)
+			}
+			if err := assertCmd.Execute(ctx); err != nil {
+				return nil, fmt.Errorf("assertion failed: %w", err)
+			}
+			return nil, nil
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:      "Assert that",
+		Namespace: "instacli/testing",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("Assert that", data)
+			if err != nil {
+				return nil, err
+			}
+			assertCmd, err := testing.NewAssertThat(m)
+			if err != nil {
+				return nil, err
+			}
+			if err := assertCmd.Execute(); err != nil {
+				return nil, fmt.Errorf("assertion failed: %w", err)
+			}
+			return nil, nil
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:         "Expected output",
+		Namespace:    "instacli/testing",
+		HandlesLists: true,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return nil, testing.NewExpectedOutputCommand(data).Execute(ctx)
+		},
+	})
+
+	library.Register(&commands.Definition{
+		Name:      "Expected console output",
+		Namespace: "instacli/testing",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return nil, testing.NewExpectedConsoleOutputCommand(data).Execute(ctx)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:      "Answers",
+		Namespace: "instacli/testing",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("Answers", data)
+			if err != nil {
+				return nil, err
+			}
+			return nil, userinteraction.NewAnswersCommand(m).Execute(ctx)
+		},
+	})
+
+	// Util
+	library.Register(&commands.Definition{
+		Name:         "Print",
+		Namespace:    "instacli/util",
+		HandlesLists: true,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			output := values.Text(data)
+			fmt.Fprintln(ctx.Console(), output)
+			return output, nil
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:         "Print JSON",
+		Namespace:    "instacli/util",
+		Schema:       "Print",
+		HandlesLists: true,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			output, err := util.FormatJSON(data, true)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintln(ctx.Console(), output)
+			return output, nil
+		},
+	})
+	for _, name := range []string{"Json", "To JSON"} {
+		library.Register(&commands.Definition{
+			Name:         name,
+			Namespace:    "instacli/util",
+			HandlesLists: true,
+			Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+				return util.FormatJSON(data, false)
+			},
+		})
+	}
+	library.Register(&commands.Definition{
+		Name:      "Base64 encode",
+		Namespace: "instacli/util",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return util.Base64Encode(data)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:      "Base64 decode",
+		Namespace: "instacli/util",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return util.Base64Decode(data)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:      "Wait",
+		Namespace: "instacli/util",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return nil, util.Wait(ctx.Context(), data)
+		},
+	})
+
+	// Regular expressions
+	library.Register(&commands.Definition{
+		Name:         "Regex",
+		HandlesLists: true,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return regex.Build(data)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:         "Match",
+		PipeProperty: "text",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("Match", data)
+			if err != nil {
+				return nil, err
+			}
+			matchCmd, err := regex.NewMatchCommand(m)
+			if err != nil {
+				return nil, err
+			}
+			return matchCmd.Execute(), nil
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:         "Replace with regex",
+		PipeProperty: "text",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("Replace with regex", data)
+			if err != nil {
+				return nil, err
+			}
+			replaceCmd, err := regex.NewReplaceCommand(m)
+			if err != nil {
+				return nil, err
+			}
+			return replaceCmd.Execute(), nil
+		},
+	})
+
+	// Files
+	library.Register(&commands.Definition{
+		Name:      "Read file",
+		Namespace: "instacli/files",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			file, err := files.ResolvePath(ctx, "Read file", data)
+			if err != nil {
+				return nil, err
+			}
+			return files.NewReadFileCommand(file).Execute()
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:         "Write file",
+		Namespace:    "instacli/files",
+		PipeProperty: "content",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			writeCmd, err := files.NewWriteFileCommand(ctx, data)
+			if err != nil {
+				return nil, err
+			}
+			return nil, writeCmd.Execute()
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:            "Temp file",
+		Namespace:       "instacli/files",
+		HandlesLists:    true,
+		DelayedResolver: true,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			tempFileCmd, err := files.NewTempFileCommand(data)
+			if err != nil {
+				return nil, err
+			}
+			return tempFileCmd.Execute(ctx)
+		},
+	})
+
+	library.Register(&commands.Definition{
+		Name:      "Run script",
+		Namespace: "instacli/files",
+		Handler:   handleRunScript,
+	})
+
+	// Shell
+	library.Register(&commands.Definition{
+		Name:      "Cli",
+		Namespace: "instacli/shell",
+		Handler:   CliCommand,
+	})
+
+	// User interaction
+	library.Register(&commands.Definition{
+		Name:      "Prompt",
+		Namespace: "instacli/user-interaction",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			promptCmd, err := userinteraction.NewPromptCommand(data)
+			if err != nil {
+				return nil, err
+			}
+			return promptCmd.Execute(ctx)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:      "Confirm",
+		Namespace: "instacli/user-interaction",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return userinteraction.NewConfirmCommand(data).Execute(ctx)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:            "Prompt object",
+		Namespace:       "instacli/user-interaction",
+		DelayedResolver: true,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("Prompt object", data)
+			if err != nil {
+				return nil, err
+			}
+			order := commands.ObjectKeys(ctx.CommandNode(), m)
+			return userinteraction.NewPromptObjectCommand(m, order).Execute(ctx)
+		},
+	})
+
+	// Types and schemas
+	library.Register(&commands.Definition{
+		Name:         "Validate type",
+		Namespace:    "instacli/types",
+		Schema:       "ValidateType",
+		PipeProperty: "item",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("Validate type", data)
+			if err != nil {
+				return nil, err
+			}
+			validateCmd, err := schema.NewValidateTypeCommand(m)
+			if err != nil {
+				return nil, err
+			}
+			return nil, validateCmd.Execute(ctx)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:         "Validate schema",
+		Namespace:    "instacli/schema",
+		Schema:       "ValidateSchema",
+		PipeProperty: "data",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("Validate schema", data)
+			if err != nil {
+				return nil, err
+			}
+			validateCmd, err := schema.NewValidateSchemaCommand(m)
+			if err != nil {
+				return nil, err
+			}
+			return nil, validateCmd.Execute(ctx)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:      "Type to JSON schema",
+		Namespace: "instacli/types",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			convertCmd, err := schema.NewTypeToJSONSchemaCommand(data)
+			if err != nil {
+				return nil, err
+			}
+			return convertCmd.Execute(ctx)
+		},
+	})
+	library.Register(&commands.Definition{
+		Name:      "JSON schema to type",
+		Namespace: "instacli/schema",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("JSON schema to type", data)
+			if err != nil {
+				return nil, err
+			}
+			return schema.NewJSONSchemaToTypeCommand(m).Execute(ctx)
+		},
+	})
+
+	// Database
+	library.Register(&commands.Definition{
+		Name:      "Store",
+		Namespace: "instacli/db",
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			m, err := commands.ObjectData("Store", data)
+			if err != nil {
+				return nil, err
+			}
+			storeCmd, err := db.NewStoreCommand(m)
+			if err != nil {
+				return nil, err
+			}
+			// Store sets the output itself when there is a query
+			return nil, storeCmd.Execute(ctx)
+		},
+	})
+
+	library.MarkBuiltin()
+	return library
+}
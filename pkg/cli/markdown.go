@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarkdownExtension is the file extension of Instacli specification documents with code examples
+const MarkdownExtension = ".spec.md"
+
+// MarkdownBlockType identifies a block in an Instacli Markdown document by the lines that start and end it
+type MarkdownBlockType struct {
+	Name            string
+	FirstLinePrefix string
+	LastLinePrefix  string
+}
+
+// Block types of Instacli Markdown documents
+var (
+	TextBlock               = &MarkdownBlockType{"text", "", "```"}
+	HeaderBlock             = &MarkdownBlockType{"header", "#", ""}
+	HiddenYamlInstacliBlock = &MarkdownBlockType{"hidden yaml instacli", "<!-- yaml instacli", "-->"}
+	YamlInstacliBlock       = &MarkdownBlockType{"yaml instacli", "```yaml instacli", "```"}
+	YamlFileBlock           = &MarkdownBlockType{"yaml file", "```yaml file", "```"}
+	ShellCliBlock           = &MarkdownBlockType{"shell cli", "```shell cli", "```"}
+	ShellBlock              = &MarkdownBlockType{"shell", "```shell", "```"}
+	AnswersBlock            = &MarkdownBlockType{"answers", "<!-- answers", "-->"}
+	OutputBlock             = &MarkdownBlockType{"output", "```output", "```"}
+)
+
+// markdownBlockTypes are the block types in the order they are recognized. Text comes last because it matches any line.
+var markdownBlockTypes = []*MarkdownBlockType{
+	YamlFileBlock,
+	HiddenYamlInstacliBlock,
+	YamlInstacliBlock,
+	ShellCliBlock,
+	ShellBlock,
+	AnswersBlock,
+	OutputBlock,
+	HeaderBlock,
+}
+
+// MarkdownBlock is a block of lines in a Markdown document
+type MarkdownBlock struct {
+	Type *MarkdownBlockType
+	// HeaderLine is the line that starts the block, for example "```yaml instacli"
+	HeaderLine string
+	Lines      []string
+	// Line is the line number in the document of the header line, or the first line of a text block
+	Line int
+}
+
+// Option returns the value of an option on the header line, for example 'cd' in "```shell cli cd=samples"
+func (b *MarkdownBlock) Option(name string) string {
+	match := regexp.MustCompile(regexp.QuoteMeta(name) + `=(\S+)`).FindStringSubmatch(b.HeaderLine)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// Content returns the lines of the block as text
+func (b *MarkdownBlock) Content() string {
+	return strings.Join(b.Lines, "\n")
+}
+
+// ScanMarkdown splits a Markdown document into blocks. Each line is looked at on its own: a line in a text block
+// that starts another block starts it, also inside a code block of another kind.
+func ScanMarkdown(data []byte) []*MarkdownBlock {
+	var blocks []*MarkdownBlock
+	current := &MarkdownBlock{Type: TextBlock, Line: 1}
+	blocks = append(blocks, current)
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lineNumber := i + 1
+		switch {
+		case current.Type == TextBlock:
+			blockType := startOfBlock(line)
+			if blockType == nil {
+				current.Lines = append(current.Lines, line)
+				continue
+			}
+			if blockType == HeaderBlock {
+				// A header is a block of one line
+				blocks = append(blocks, &MarkdownBlock{Type: HeaderBlock, HeaderLine: line, Line: lineNumber})
+				current = &MarkdownBlock{Type: TextBlock, Line: lineNumber + 1}
+			} else {
+				current = &MarkdownBlock{Type: blockType, HeaderLine: line, Line: lineNumber}
+			}
+			blocks = append(blocks, current)
+		case strings.HasPrefix(line, current.Type.LastLinePrefix):
+			current = &MarkdownBlock{Type: TextBlock, Line: lineNumber + 1}
+			blocks = append(blocks, current)
+		default:
+			current.Lines = append(current.Lines, line)
+		}
+	}
+
+	return blocks
+}
+
+func startOfBlock(line string) *MarkdownBlockType {
+	for _, blockType := range markdownBlockTypes {
+		if strings.HasPrefix(line, blockType.FirstLinePrefix) {
+			return blockType
+		}
+	}
+	return nil
+}
+
+// MarkdownDescription returns the first line of text in a Markdown document that is not a header or a comment, or an
+// empty string if there is none
+func MarkdownDescription(blocks []*MarkdownBlock) string {
+	for _, block := range blocks {
+		if block.Type != TextBlock {
+			continue
+		}
+		for _, line := range block.Lines {
+			if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "<!--") &&
+				!strings.HasPrefix(line, "```") {
+				return line
+			}
+		}
+	}
+	return ""
+}
+
+// MarkdownSection is the part of a Markdown document under a header
+type MarkdownSection struct {
+	// Title is the text of the header, or empty for the part before the first header
+	Title  string
+	Line   int
+	Blocks []*MarkdownBlock
+}
+
+// SplitMarkdownSections groups blocks by the header they are under
+func SplitMarkdownSections(blocks []*MarkdownBlock) []*MarkdownSection {
+	current := &MarkdownSection{Line: 1}
+	sections := []*MarkdownSection{current}
+	for _, block := range blocks {
+		if block.Type == HeaderBlock {
+			current = &MarkdownSection{
+				Title: strings.TrimSpace(strings.TrimLeft(block.HeaderLine, "#")),
+				Line:  block.Line,
+			}
+			sections = append(sections, current)
+		}
+		current.Blocks = append(current.Blocks, block)
+	}
+	return sections
+}
+
+// Commands returns the Instacli commands in the section
+func (s *MarkdownSection) Commands() ([]commands.Command, error) {
+	return markdownCommands(s.Blocks)
+}
+
+// ParseMarkdownScript turns a Markdown document into a script with the commands of all its blocks.
+// The console output is captured from the start, so output blocks can check it.
+func ParseMarkdownScript(data []byte) (*ParsedScript, error) {
+	blocks := ScanMarkdown(data)
+	cmds, err := markdownCommands(blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	script := &ParsedScript{Commands: cmds, CaptureOutput: true}
+	for _, block := range blocks {
+		if block.Type != YamlInstacliBlock && block.Type != HiddenYamlInstacliBlock {
+			continue
+		}
+		// The script info is in one of the blocks
+		parsed, err := ParseScript([]byte(block.Content()))
+		if err == nil && hasCommand(parsed, ScriptInfoCommand) {
+			script.Metadata = parsed.Metadata
+		}
+	}
+	return script, nil
+}
+
+func hasCommand(script *ParsedScript, name string) bool {
+	for _, cmd := range script.Commands {
+		if cmd.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// markdownCommands converts blocks to commands:
+//   - yaml instacli blocks, visible or hidden, are run as they are
+//   - yaml file blocks create a file in the temp dir, with the name of the 'file' option
+//   - shell cli blocks run the Instacli command line, in the directory of the 'cd' option
+//   - answers blocks provide answers to prompts
+//   - output blocks check the console output
+//
+// Shell blocks and shell cli blocks marked 'ignore' are documentation only. The line numbers of the commands refer
+// to the document.
+func markdownCommands(blocks []*MarkdownBlock) ([]commands.Command, error) {
+	var cmds []commands.Command
+	for _, block := range blocks {
+		switch block.Type {
+		case YamlInstacliBlock, HiddenYamlInstacliBlock:
+			script, err := ParseScript([]byte(block.Content()))
+			if err != nil {
+				return nil, fmt.Errorf("%s block on line %d: %w", block.Type.Name, block.Line, err)
+			}
+			for _, cmd := range script.Commands {
+				cmd.Line += block.Line
+				cmds = append(cmds, cmd)
+			}
+		case YamlFileBlock:
+			filename := block.Option("file")
+			if filename == "" {
+				return nil, fmt.Errorf("yaml file block on line %d: no file specified", block.Line)
+			}
+			cmds = append(cmds, commands.Command{Name: "Temp file", Line: block.Line, Data: map[string]interface{}{
+				"filename": filename,
+				"content":  block.Content(),
+				"resolve":  block.Option("resolve") == "true",
+			}})
+		case ShellCliBlock:
+			if strings.Contains(block.HeaderLine, "ignore") {
+				continue
+			}
+			data := map[string]interface{}{"command": block.Content()}
+			if cd := block.Option("cd"); cd != "" {
+				data["cd"] = cd
+			}
+			cmds = append(cmds, commands.Command{Name: "Cli", Data: data, Line: block.Line})
+		case AnswersBlock:
+			var answers map[string]interface{}
+			if err := yaml.Unmarshal([]byte(block.Content()), &answers); err != nil {
+				return nil, fmt.Errorf("answers block on line %d: %w", block.Line, err)
+			}
+			cmds = append(cmds, commands.Command{Name: "Answers", Data: answers, Line: block.Line})
+		case OutputBlock:
+			cmds = append(cmds, commands.Command{Name: "Expected console output", Data: block.Content(), Line: block.Line})
+		}
+	}
+	return cmds, nil
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownScript(t *testing.T) {
+	dir := t.TempDir()
+	document := filepath.Join(dir, "greeting.md")
+	content := "# Greeting\n\n" +
+		"```yaml file=greet.cli\nPrint: Hello from greet!\n```\n\n" +
+		"```yaml instacli\nPrint: Running greet\n```\n\n" +
+		"```shell cli\ncli greet.cli\n```\n\n" +
+		"```output\nRunning greet\nHello from greet!\n```\n\n" +
+		"Shell blocks are documentation only\n\n" +
+		"```shell\nexit 1\n```\n"
+	if err := os.WriteFile(document, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	script := NewScript(document, false, false, false, true)
+	script.Out = &out
+	if err := script.Execute(); err != nil {
+		t.Fatalf("Script execution error: %v\n%s", err, out.String())
+	}
+	if out.String() != "Running greet\nHello from greet!\n" {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+}
+
+func TestMarkdownOutputMismatch(t *testing.T) {
+	dir := t.TempDir()
+	document := filepath.Join(dir, "hello.spec.md")
+	content := "## Hello\n\n" +
+		"```yaml file=hello.cli\nPrint: Hello\n```\n\n" +
+		"```shell cli\ncli hello.cli\n```\n\n" +
+		"```output\nGoodbye\n```\n"
+	if err := os.WriteFile(document, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases, err := LoadTestCases(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveTempDirs(testCases)
+	if len(testCases) != 1 || testCases[0].Name != "Hello" {
+		t.Fatalf("Expected one test case named Hello, got %v", testCases)
+	}
+	result := RunTestCase(testCases[0])
+	if result.Passed() || !strings.Contains(result.Err.Error(), "  - Goodbye\n  + Hello") {
+		t.Errorf("Expected console output mismatch, got: %v", result.Err)
+	}
+}
+
+func TestScanMarkdownAfterIndentedFence(t *testing.T) {
+	content := "## First\n\n ```yaml instacli\n  Print: indented\n```\n\n## Second\n\n```yaml instacli\nPrint: found\n```\n"
+	sections := SplitMarkdownSections(ScanMarkdown([]byte(content)))
+	last := sections[len(sections)-1]
+	if last.Title != "Second" {
+		t.Fatalf("Expected the section after the indented fence, got: %q", last.Title)
+	}
+	var found bool
+	for _, block := range last.Blocks {
+		found = found || block.Type == YamlInstacliBlock && block.Content() == "Print: found"
+	}
+	if !found {
+		t.Error("Expected the code block of the second section")
+	}
+}
@@ -1,8 +1,8 @@
 package cli
 
 import (
+	_ "embed"
 	"fmt"
-	"sort"
 	"strings"
 
 	"instacli/pkg/spec"
@@ -12,31 +12,69 @@ import (
 
 // Option represents a command-line option configuration
 type Option struct {
+	Name        string `yaml:"-"`
 	Description string `yaml:"description"`
 	Default     bool   `yaml:"default,omitempty"`
 	Type        string `yaml:"type,omitempty"`
 	ShortOption string `yaml:"short option,omitempty"`
+	// Hidden options are not listed in the help
+	Hidden bool `yaml:"-"`
 }
 
-// Options represents the command-line options configuration
-type Options map[string]Option
+// extraOptions holds the options of this implementation that are not in the spec. They are hidden, so the help is
+// the one of the spec.
+//
+//go:embed options.yaml
+var extraOptions []byte
 
-// LoadOptions loads the command-line options from the embedded YAML file
+// Options are the command-line options in the order they are defined
+type Options []*Option
+
+// LoadOptions loads the command-line options of the spec, followed by the ones of this implementation
 func LoadOptions() (Options, error) {
 	data, err := spec.GetSpecFile("cli/instacli-command-line-options.yaml")
 	if err != nil {
 		return nil, fmt.Errorf("error reading options file: %w", err)
 	}
 
-	var options Options
-	if err := yaml.Unmarshal(data, &options); err != nil {
+	options, err := parseOptions(data, false)
+	if err != nil {
 		return nil, fmt.Errorf("error parsing options file: %w", err)
 	}
+	extra, err := parseOptions(extraOptions, true)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing extra options: %w", err)
+	}
+
+	return append(options, extra...), nil
+}
 
+// parseOptions reads options from YAML with an entry for each option, keeping their order
+func parseOptions(data []byte, hidden bool) (Options, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("options must be an object with an entry for each option")
+	}
+	var options Options
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		opt := &Option{Name: root.Content[i].Value, Hidden: hidden}
+		if err := root.Content[i+1].Decode(opt); err != nil {
+			return nil, fmt.Errorf("option '%s': %w", opt.Name, err)
+		}
+		options = append(options, opt)
+	}
 	return options, nil
 }
 
-// FormatHelp formats the help text for the options
+// FormatHelp formats the help text for the options that are not hidden. The descriptions line up after the
+// longest option name.
 func (o Options) FormatHelp() string {
 	var help strings.Builder
 	help.WriteString("Instacli -- Instantly create CLI applications with light scripting!\n\n")
@@ -44,20 +82,21 @@ func (o Options) FormatHelp() string {
 	help.WriteString("   cli [global options] file | directory [command options]\n\n")
 	help.WriteString("Global options:\n")
 
-	// Sort options by name for consistent output
-	keys := make([]string, 0, len(o))
-	for k := range o {
-		keys = append(keys, k)
+	width := 0
+	for _, opt := range o {
+		if !opt.Hidden {
+			width = max(width, len(opt.Name)+2)
+		}
 	}
-	sort.Strings(keys)
-
-	for _, name := range keys {
-		opt := o[name]
-		shortOpt := ""
+	for _, opt := range o {
+		if opt.Hidden {
+			continue
+		}
+		key := "--" + opt.Name
 		if opt.ShortOption != "" {
-			shortOpt = fmt.Sprintf(", -%s", opt.ShortOption)
+			key += ", -" + opt.ShortOption
 		}
-		help.WriteString(fmt.Sprintf("  --%s%s   %s\n", name, shortOpt, opt.Description))
+		help.WriteString(fmt.Sprintf("  %-*s   %s\n", width, key, strings.TrimSpace(opt.Description)))
 	}
 
 	return help.String()
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/commands/variables"
+)
+
+// PipeOperator feeds the output of the previous command into the next one. '=> Print:' prints the output, and
+// '=>: ${name}' stores it in a variable, like As does.
+const PipeOperator = "=>"
+
+// pipedValue is the data that the output is piped in as. The command runs as if it was written there.
+const pipedValue = "${output}"
+
+// isPipe tells if a command name starts with the pipe operator
+func isPipe(name string) bool {
+	return name == PipeOperator || strings.HasPrefix(name, PipeOperator+" ")
+}
+
+// isPipedAssignment tells if a command name has the form '${value} =>', which stores the value in the variable
+// given as data
+func isPipedAssignment(name string) bool {
+	return strings.HasSuffix(name, " "+PipeOperator)
+}
+
+// runPipe runs a command that starts with the pipe operator
+func runPipe(ctx *commands.ExecutionContext, cmd commands.Command) error {
+	if ctx.GetOutput() == nil {
+		return fmt.Errorf("line %d: Nothing to pipe into '%s', there is no output", cmd.Line, cmd.Name)
+	}
+	if cmd.Name == PipeOperator {
+		return assignTo(ctx, cmd, ctx.GetOutput())
+	}
+
+	cmd.Name = strings.TrimSpace(strings.TrimPrefix(cmd.Name, PipeOperator))
+	def, err := findCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	data, err := pipeInto(def, cmd.Data)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", cmd.Line, err)
+	}
+	cmd.Data = data
+	// The source no longer matches the data
+	cmd.Node = nil
+	return runDefinition(ctx, def, cmd)
+}
+
+// pipeInto adds the piped output to the data of a command:
+//   - Without data, the output is the data.
+//   - In a list, the output is put in front.
+//   - In an object, the output is the property of the command that takes piped input, like 'actual' for Assert
+//     equals.
+//   - A command that handles lists gets a list with the output and the data.
+func pipeInto(def *commands.Definition, data interface{}) (interface{}, error) {
+	switch d := data.(type) {
+	case nil:
+		return pipedValue, nil
+	case []interface{}:
+		return append([]interface{}{pipedValue}, d...), nil
+	case map[string]interface{}:
+		if def.PipeProperty == "" {
+			return nil, fmt.Errorf("Command '%s' does not take piped input with an object", def.Name)
+		}
+		if _, ok := d[def.PipeProperty]; ok {
+			return nil, fmt.Errorf("Command '%s' takes piped input as '%s', which is already given", def.Name, def.PipeProperty)
+		}
+		piped := make(map[string]interface{}, len(d)+1)
+		for key, value := range d {
+			piped[key] = value
+		}
+		piped[def.PipeProperty] = pipedValue
+		return piped, nil
+	}
+	if def.HandlesLists {
+		return []interface{}{pipedValue, data}, nil
+	}
+	return nil, fmt.Errorf("Command '%s' does not take piped input with a value", def.Name)
+}
+
+// runPipedAssignment runs '${value} =>: ${name}', which stores the value in a variable
+func runPipedAssignment(ctx *commands.ExecutionContext, cmd commands.Command) error {
+	source := strings.TrimSpace(strings.TrimSuffix(cmd.Name, PipeOperator))
+	value, err := variables.ResolveVariables(source, ctx.Vars(), ctx.Strict())
+	if err != nil {
+		return fmt.Errorf("line %d: error resolving variables in %s: %w", cmd.Line, source, err)
+	}
+	return assignTo(ctx, cmd, value)
+}
+
+// assignTo stores a value in the variable given as the data of the command, in the form ${name}
+func assignTo(ctx *commands.ExecutionContext, cmd commands.Command, value interface{}) error {
+	name, _ := cmd.Data.(string)
+	if !commands.IsVariableAssignment(name) {
+		return fmt.Errorf("line %d: %s: variable name must be in ${var} format", cmd.Line, cmd.Name)
+	}
+	ctx.SetVar(name[2:len(name)-1], value)
+	return nil
+}
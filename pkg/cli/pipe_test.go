@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		err    string
+	}{
+		{"Store the output", "Output: Hello\n=>: ${greeting}\nAssert equals: {actual: '${greeting}', expected: Hello}\n", ""},
+		{"Store a value", "${greeting}: {text: Hello}\n${greeting.text} =>: ${text}\n" +
+			"Assert equals: {actual: '${text}', expected: Hello}\n", ""},
+		{"Pipe as data", "Output: Hello\n=> Print:\nExpected console output: Hello\n", ""},
+		{"Pipe into a command that handles lists", "Output: 1\n=> Output: 2\nExpected output: [1, 2]\n", ""},
+		{"Pipe into an object", "Output: [1, 2]\n=> Assert equals:\n  expected: [1, 2]\n", ""},
+		{"Pipe into a list", "Output: Hello\n=> Expected output: [Hello]\n", "Unexpected output"},
+		{"Property already given", "Output: Hello\n=> Assert equals: {actual: a, expected: a}\n", "already given"},
+		{"No pipe property", "Output: Hello\n=> Read file: {file: a.txt}\n", "does not take piped input"},
+		{"No output", "=> Print:\n", "there is no output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := ParseScript([]byte(tt.script))
+			if err != nil {
+				t.Fatal(err)
+			}
+			script.CaptureOutput = true
+			err = executeScript(script, nil, true, &strings.Builder{}, nil)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("Unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("Expected error containing %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
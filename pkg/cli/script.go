@@ -1,8 +1,16 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/types"
 )
 
 // Script represents a CLI script to be executed
@@ -12,7 +20,17 @@ type Script struct {
 	Output         bool
 	OutputJSON     bool
 	NonInteractive bool
-	parsedScript   *ParsedScript
+	// Strict makes a reference to an unknown variable an error
+	Strict bool
+	// Context stops the script when it is done, like on Ctrl-C
+	Context context.Context
+	// Input holds the input parameters given on the command line
+	Input map[string]string
+	// Out is where the script prints its output. Standard output is used if it is nil.
+	Out io.Writer
+	// UserInteraction asks for input. If it is nil, the user is asked on the terminal, unless NonInteractive is set.
+	UserInteraction commands.UserInteraction
+	parsedScript    *ParsedScript
 }
 
 // NewScript creates a new Script instance
@@ -26,6 +44,29 @@ func NewScript(path string, debug, output, outputJSON, nonInteractive bool) *Scr
 	}
 }
 
+// ParseInputArgs reads the input parameters of a script from the command line arguments after the script,
+// in the form --name value or --name=value. A short option is given as -n value.
+func ParseInputArgs(args []string) (map[string]string, error) {
+	input := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if !strings.HasPrefix(arg, "-") || name == "" {
+			return nil, fmt.Errorf("unexpected argument: %s", arg)
+		}
+		if before, after, found := strings.Cut(name, "="); found {
+			input[before] = after
+			continue
+		}
+		if i+1 == len(args) {
+			return nil, fmt.Errorf("missing value for --%s", name)
+		}
+		input[name] = args[i+1]
+		i++
+	}
+	return input, nil
+}
+
 // Execute runs the script
 func (s *Script) Execute() error {
 	// Check if path exists
@@ -40,45 +81,100 @@ func (s *Script) Execute() error {
 	return s.handleFile()
 }
 
+// handleDirectory lists the commands in the directory
 func (s *Script) handleDirectory() error {
-	// TODO: Implement directory handling
-	// This should list available commands in the directory
-	return fmt.Errorf("directory handling not implemented yet")
+	help, err := DirectoryHelp(s.Path)
+	if err != nil {
+		return err
+	}
+	out := s.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, err = fmt.Fprint(out, help)
+	return err
 }
 
 func (s *Script) handleFile() error {
-	// Read the script file
-	data, err := os.ReadFile(s.Path)
-	if err != nil {
-		return fmt.Errorf("error reading script file: %w", err)
+	if err := s.parse(); err != nil {
+		return err
 	}
+	script := s.parsedScript
+	script.File = s.Path
+	script.Dir = filepath.Dir(s.Path)
+	script.Strict = s.Strict
+	script.Context = s.Context
 
-	// Parse the script
-	script, err := ParseScript(data)
-	if err != nil {
-		return fmt.Errorf("error parsing script: %w", err)
+	// Load the types defined next to the script
+	script.Types = types.NewRegistry()
+	if err := script.Types.LoadDir(script.Dir); err != nil {
+		return err
 	}
-	s.parsedScript = script
+	script.Metadata.Input.ExpandShortOptions(s.Input)
 
-	// Execute the script with empty input for now
-	return ExecuteScript(script, nil)
+	out := s.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	return executeScript(script, s.Input, s.NonInteractive, out, s.UserInteraction)
 }
 
-// GetScriptHelp returns help information for a script
+// GetScriptHelp returns help information for a script, or the commands of a directory
 func (s *Script) GetScriptHelp() (string, error) {
-	if s.parsedScript == nil {
-		// Read and parse the script if not already done
-		data, err := os.ReadFile(s.Path)
-		if err != nil {
-			return "", fmt.Errorf("error reading script file: %w", err)
-		}
+	if info, err := os.Stat(s.Path); err == nil && info.IsDir() {
+		return DirectoryHelp(s.Path)
+	}
+	if err := s.parse(); err != nil {
+		return "", err
+	}
+	registry := types.NewRegistry()
+	if err := registry.LoadDir(filepath.Dir(s.Path)); err != nil {
+		return "", err
+	}
+	s.parsedScript.Types = registry
+	return GetScriptHelp(s.parsedScript)
+}
 
-		script, err := ParseScript(data)
-		if err != nil {
-			return "", fmt.Errorf("error parsing script: %w", err)
-		}
-		s.parsedScript = script
+// EmitSchema returns a JSON Schema of the input of the script
+func (s *Script) EmitSchema() (string, error) {
+	if err := s.parse(); err != nil {
+		return "", err
+	}
+	registry := types.NewRegistry()
+	if err := registry.LoadDir(filepath.Dir(s.Path)); err != nil {
+		return "", err
+	}
+	schema, err := registry.ToJSONSchema(s.parsedScript.Metadata.InputType())
+	if err != nil {
+		return "", err
+	}
+	if s.parsedScript.Metadata.Description != "" {
+		schema["description"] = s.parsedScript.Metadata.Description
 	}
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
 
-	return GetScriptHelp(s.parsedScript), nil
+// parse reads and parses the script if not already done. A Markdown document is run as a script.
+func (s *Script) parse() error {
+	if s.parsedScript != nil {
+		return nil
+	}
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return fmt.Errorf("error reading script file: %w", err)
+	}
+	parse := ParseScript
+	if strings.HasSuffix(s.Path, ".md") {
+		parse = ParseMarkdownScript
+	}
+	script, err := parse(data)
+	if err != nil {
+		return fmt.Errorf("error parsing script: %w", err)
+	}
+	s.parsedScript = script
+	return nil
 }
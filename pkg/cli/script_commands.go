@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/commands/files"
+	"instacli/pkg/cli/types"
+	"instacli/pkg/values"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarkdownScriptExtension is the file extension of Instacli scripts written as Markdown
+const MarkdownScriptExtension = ".cli.md"
+
+// DirectoryInfoFile configures the scripts in a directory
+const DirectoryInfoFile = ".instacli.yaml"
+
+// MaxScriptDepth is how deep scripts can call other scripts, to stop recursion that does not end
+const MaxScriptDepth = 100
+
+// DirectoryInfo holds the settings in the .instacli.yaml file of a directory
+type DirectoryInfo struct {
+	// Imports are scripts in other directories that can be called as commands, relative to the directory
+	Imports []string `yaml:"imports"`
+	// ScriptInfo describes the directory when its commands are listed
+	ScriptInfo ScriptMetadata `yaml:"Script info"`
+	// Hidden directories are not listed as commands of the directory they are in
+	Hidden bool `yaml:"hidden"`
+}
+
+// LoadDirectoryInfo reads the .instacli.yaml file of a directory. A directory without one has no settings.
+func LoadDirectoryInfo(dir string) (*DirectoryInfo, error) {
+	info := &DirectoryInfo{}
+	data, err := os.ReadFile(filepath.Join(dir, DirectoryInfoFile))
+	if os.IsNotExist(err) {
+		return info, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", filepath.Join(dir, DirectoryInfoFile), err)
+	}
+	return info, nil
+}
+
+// ScriptCommandName returns the command that runs a script file: the file name without extension, with spaces
+// for dashes and starting with a capital. For example, create-greeting.cli is called with 'Create greeting'.
+func ScriptCommandName(fileName string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(fileName, MarkdownScriptExtension), ScriptExtension)
+	name = strings.ReplaceAll(name, "-", " ")
+	first, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(first)) + name[size:]
+}
+
+// FindScriptCommand returns the script that a command name refers to: a script in the directory, or a script that
+// the directory imports. It returns an empty string if there is none.
+func FindScriptCommand(dir string, command string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && isScriptFile(entry.Name()) && ScriptCommandName(entry.Name()) == command {
+			return filepath.Join(dir, entry.Name()), nil
+		}
+	}
+
+	info, err := LoadDirectoryInfo(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, imported := range info.Imports {
+		if ScriptCommandName(filepath.Base(imported)) == command {
+			return filepath.Join(dir, imported), nil
+		}
+	}
+	return "", nil
+}
+
+func isScriptFile(name string) bool {
+	return strings.HasSuffix(name, ScriptExtension) || strings.HasSuffix(name, MarkdownScriptExtension)
+}
+
+// scriptCommand returns a command that runs the script with the name of the command, from the directory of the
+// running script, or a command defined in a script that the directory imports. It returns nil if there is none.
+func scriptCommand(ctx *commands.ExecutionContext, name string) (*commands.Definition, error) {
+	if ctx.ScriptDir() == "" {
+		return nil, nil
+	}
+	file, err := FindScriptCommand(ctx.ScriptDir(), name)
+	if err != nil {
+		return nil, err
+	}
+	if file == "" {
+		return importedCommand(ctx.Commands(), ctx.ScriptDir(), name)
+	}
+	return &commands.Definition{
+		Name: name,
+		Handler: func(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+			return RunScriptFile(ctx, file, data)
+		},
+	}, nil
+}
+
+// handleRunScript runs the Run script command. The short form is the path of the script relative to the directory
+// of the running script. The long form has 'file' or 'resource', and the input.
+func handleRunScript(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+	if file, ok := data.(string); ok {
+		return RunScriptFile(ctx, filepath.Join(ctx.ScriptDir(), file), nil)
+	}
+
+	m, err := commands.ObjectData("Run script", data)
+	if err != nil {
+		return nil, err
+	}
+	file, err := files.ResolvePath(ctx, "Run script", m)
+	if err != nil {
+		return nil, err
+	}
+	return RunScriptFile(ctx, file, m["input"])
+}
+
+// LoadScript reads a script file, or a Markdown document as a script, with the types defined next to it
+func LoadScript(file string) (*ParsedScript, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading script file: %w", err)
+	}
+	parse := ParseScript
+	if strings.HasSuffix(file, ".md") {
+		parse = ParseMarkdownScript
+	}
+	script, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing script %s: %w", file, err)
+	}
+	script.File = file
+	script.Dir = filepath.Dir(file)
+	script.Types = types.NewRegistry()
+	if err := script.Types.LoadDir(script.Dir); err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+// RunScriptFile runs a script from another script, with the given input. The script has variables of its own.
+// Its output is returned.
+func RunScriptFile(ctx *commands.ExecutionContext, file string, input interface{}) (interface{}, error) {
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+	m, ok := input.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("input of %s must be an object, got: %v", filepath.Base(file), input)
+	}
+	if err := checkRecursion(ctx, file, m); err != nil {
+		return nil, err
+	}
+
+	script, err := LoadScript(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// The script fills in defaults in its copy of the input
+	inputVars := make(map[string]interface{}, len(m))
+	for name, value := range m {
+		inputVars[name] = value
+	}
+	// Commands defined by the caller are not available to the script
+	child := ctx.NewChild(file, m)
+	child.SetCommands(NewCommandLibrary())
+	child.SetTypes(script.Types)
+	child.SetVar(InputVariable, inputVars)
+	if err := runCommands(child, script.Commands); err != nil {
+		return nil, err
+	}
+	return child.GetOutput(), nil
+}
+
+// checkRecursion stops a script from calling itself with the same input it was called with, because that would
+// never end, and limits how deep scripts can call each other
+func checkRecursion(ctx *commands.ExecutionContext, file string, input map[string]interface{}) error {
+	file, _ = filepath.Abs(file)
+	depth := 0
+	for c := ctx; c != nil; c = c.Parent() {
+		depth++
+		caller, _ := filepath.Abs(c.ScriptFile())
+		if c.Parent() != nil && caller == file && values.Equal(c.CallInput(), input) {
+			return fmt.Errorf("Endless recursion: %s calls itself with the same input", filepath.Base(file))
+		}
+	}
+	if depth > MaxScriptDepth {
+		return fmt.Errorf("Scripts are nested more than %d levels deep when calling %s", MaxScriptDepth, filepath.Base(file))
+	}
+	return nil
+}
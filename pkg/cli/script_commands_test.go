@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScriptCommandName(t *testing.T) {
+	tests := map[string]string{
+		"create-greeting.cli": "Create greeting",
+		"hello.cli.md":        "Hello",
+		"Run-all.cli":         "Run all",
+	}
+	for file, expected := range tests {
+		if actual := ScriptCommandName(file); actual != expected {
+			t.Errorf("%s: expected %q, got %q", file, expected, actual)
+		}
+	}
+}
+
+func TestRunSiblingScripts(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.cli": "Create greeting:\n  name: World\n\n---\nPrint: ${output}\n\n---\n" +
+			"Shout:\n  text: ${output}\n\n---\nPrint: ${output}\n",
+		"create-greeting.cli": "Output: Hello ${input.name}!\n",
+		".instacli.yaml":      "imports:\n  - lib/shout.cli\n",
+		"lib/shout.cli":       "Output: ${input.text}\n",
+	})
+
+	var out strings.Builder
+	script := NewScript(filepath.Join(dir, "main.cli"), false, false, false, true)
+	script.Out = &out
+	if err := script.Execute(); err != nil {
+		t.Fatalf("Script execution error: %v\n%s", err, out.String())
+	}
+	if out.String() != "Hello World!\nHello World!\n" {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+}
+
+func TestImportFromSiblingScript(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.cli":                   "Call helper:\n  name: Ray\n\n---\nPrint: ${output}\n",
+		".instacli.yaml":             "imports:\n  - helper/helper-function.cli\n  - helper/call-helper.cli\n",
+		"helper/call-helper.cli":     "Helper function:\n  name: ${input.name}\n",
+		"helper/helper-function.cli": "Output: Thanks for your help, ${input.name}!\n",
+	})
+
+	file, err := FindScriptCommand(dir, "Helper function")
+	if err != nil || file != filepath.Join(dir, "helper", "helper-function.cli") {
+		t.Errorf("Expected the imported script, got: %q %v", file, err)
+	}
+
+	var out strings.Builder
+	script := NewScript(filepath.Join(dir, "main.cli"), false, false, false, true)
+	script.Out = &out
+	if err := script.Execute(); err != nil {
+		t.Fatalf("Script execution error: %v\n%s", err, out.String())
+	}
+	if out.String() != "Thanks for your help, Ray!\n" {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+}
+
+func TestEndlessRecursion(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"loop.cli": "Loop:\n  count: 1\n",
+	})
+
+	script := NewScript(filepath.Join(dir, "loop.cli"), false, false, false, true)
+	script.Out = &strings.Builder{}
+	if err := script.Execute(); err == nil || !strings.Contains(err.Error(), "Endless recursion") {
+		t.Errorf("Expected endless recursion error, got: %v", err)
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/commands/userinteraction"
+	"instacli/pkg/cli/commands/variables"
+	"instacli/pkg/values"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExecuteScript runs the script with the given input parameters.
+// Input parameters that are not given are asked for by the Script info command.
+// In non-interactive mode, the user is not asked for input: prompts take a prerecorded answer from the Answers
+// command or their default value, and fail otherwise.
+func ExecuteScript(script *ParsedScript, input map[string]string, nonInteractive bool) error {
+	return executeScript(script, input, nonInteractive, os.Stdout, nil)
+}
+
+// executeScript runs the script like ExecuteScript, printing to out. If ui is not nil, it is used to ask for input.
+func executeScript(script *ParsedScript, input map[string]string, nonInteractive bool, out io.Writer, ui commands.UserInteraction) error {
+	ctx, err := newScriptContext(script, input, nonInteractive, commands.NewConsole(out))
+	if err != nil {
+		return err
+	}
+	if ui != nil {
+		ctx.SetUserInteraction(ui)
+	}
+	defer os.RemoveAll(ctx.TempDir())
+	return runCommands(ctx, script.Commands)
+}
+
+// newScriptContext creates the context to run a script in, with output printed to the given console.
+// The temp dir of the context is created here and should be removed by the caller.
+func newScriptContext(script *ParsedScript, input map[string]string, nonInteractive bool, console *commands.Console) (*commands.ExecutionContext, error) {
+	ctx := commands.NewExecutionContext()
+	ctx.SetConsole(console)
+	if script.CaptureOutput {
+		console.StartCapture()
+	}
+	ctx.SetNonInteractive(nonInteractive)
+	ctx.SetStrict(script.Strict)
+	ctx.SetContext(script.Context)
+	if nonInteractive {
+		ctx.SetUserInteraction(userinteraction.NewRecordedAnswers(nil, ctx.Console()))
+	} else {
+		ctx.SetUserInteraction(userinteraction.NewTerminal(ctx.Console()))
+	}
+	ctx.SetCommands(NewCommandLibrary())
+	if script.Types != nil {
+		ctx.SetTypes(script.Types)
+	}
+	ctx.SetScriptDir(script.Dir)
+	ctx.SetScriptFile(script.File)
+
+	if script.TempDir != "" {
+		ctx.SetTempDir(script.TempDir)
+		ctx.SetScriptDir(script.TempDir)
+	} else {
+		tempDir, err := os.MkdirTemp("", "instacli-")
+		if err != nil {
+			return nil, fmt.Errorf("can not create temp dir: %w", err)
+		}
+		ctx.SetTempDir(tempDir)
+	}
+
+	if input != nil {
+		inputVars := make(map[string]interface{}, len(input))
+		for name, value := range input {
+			inputVars[name] = value
+		}
+		ctx.SetVar(InputVariable, inputVars)
+	}
+	return ctx, nil
+}
+
+// runCommands runs commands one after the other, stopping at the first error
+func runCommands(ctx *commands.ExecutionContext, cmds []commands.Command) error {
+	for _, cmd := range cmds {
+		if err := ctx.Context().Err(); err != nil {
+			return fmt.Errorf("line %d: script stopped before %s: %w", cmd.Line, cmd.Name, err)
+		}
+		if err := runCommand(ctx, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCommand runs a single command: a variable assignment, a command that the output is piped into, or a command
+// with its data
+func runCommand(ctx *commands.ExecutionContext, cmd commands.Command) error {
+	if commands.IsVariableAssignment(cmd.Name) {
+		resolved, err := variables.ResolveVariables(cmd.Data, ctx.Vars(), ctx.Strict())
+		if err != nil {
+			return fmt.Errorf("line %d: error resolving variables in %s: %w", cmd.Line, cmd.Name, err)
+		}
+		ctx.SetVar(cmd.Name[2:len(cmd.Name)-1], resolved)
+		return nil
+	}
+
+	if isPipe(cmd.Name) {
+		return runPipe(ctx, cmd)
+	}
+	if isPipedAssignment(cmd.Name) {
+		return runPipedAssignment(ctx, cmd)
+	}
+
+	def, err := findCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return runDefinition(ctx, def, cmd)
+}
+
+// findCommand returns the definition of a command: a built-in or defined command, or a script in the same directory
+func findCommand(ctx *commands.ExecutionContext, cmd commands.Command) (*commands.Definition, error) {
+	if def := ctx.Commands().Get(cmd.Name); def != nil {
+		return def, nil
+	}
+	def, err := scriptCommand(ctx, cmd.Name)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", cmd.Line, err)
+	}
+	if def == nil {
+		return nil, fmt.Errorf("line %d: Unknown command: %s", cmd.Line, cmd.Name)
+	}
+	return def, nil
+}
+
+// runDefinition runs a command with its definition. Commands that do not handle lists are run once for each item
+// in a list, and their output is collected into a list.
+func runDefinition(ctx *commands.ExecutionContext, def *commands.Definition, cmd commands.Command) error {
+	list, isList := cmd.Data.([]interface{})
+	if !isList || def.HandlesLists {
+		output, err := runSingleCommand(ctx, def, cmd.Data, cmd.Node, cmd.Line)
+		if err != nil {
+			return err
+		}
+		if output != nil {
+			ctx.SetOutput(output)
+		}
+		return nil
+	}
+
+	var outputs []interface{}
+	for i, item := range list {
+		var node *yaml.Node
+		if cmd.Node != nil && cmd.Node.Kind == yaml.SequenceNode && i < len(cmd.Node.Content) {
+			node = cmd.Node.Content[i]
+		}
+		output, err := runSingleCommand(ctx, def, item, node, cmd.Line)
+		if err != nil {
+			return err
+		}
+		if output != nil {
+			outputs = append(outputs, output)
+		}
+	}
+	if len(outputs) > 0 {
+		ctx.SetOutput(outputs)
+	}
+	return nil
+}
+
+func runSingleCommand(ctx *commands.ExecutionContext, def *commands.Definition, data interface{}, node *yaml.Node, line int) (interface{}, error) {
+	if !def.DelayedResolver {
+		resolved, err := variables.ResolveVariables(data, ctx.Vars(), ctx.Strict())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: error resolving variables in %s: %w", line, def.Name, err)
+		}
+		data = resolved
+	}
+
+	if err := validateArguments(def, data, line); err != nil {
+		return nil, err
+	}
+
+	ctx.SetCommandNode(node)
+	defer ctx.SetCommandNode(nil)
+	output, err := def.Handler(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return values.Normalize(output), nil
+}
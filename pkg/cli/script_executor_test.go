@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"instacli/pkg/cli/commands"
+)
+
+func TestCommandsRunInOrder(t *testing.T) {
+	script, err := ParseScript([]byte(`
+Output: one
+As: ${first}
+
+Output: two
+As: ${second}
+
+Output: [three, four]
+
+Expected output: [three, four]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, cmd := range script.Commands {
+		names = append(names, cmd.Name)
+	}
+	expected := []string{"Output", "As", "Output", "As", "Output", "Expected output"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Not equal:\n  Expected: %v\n  Actual:   %v", expected, names)
+	}
+	if err := ExecuteScript(script, nil, false); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInvalidCommandFormat(t *testing.T) {
+	script, err := ParseScript([]byte(`Test case: Invalid command format
+
+Assert equals:
+  actual: something
+  expectd: something
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ExecuteScript(script, nil, false)
+	var formatErr *commands.CommandFormatError
+	if !errors.As(err, &formatErr) {
+		t.Fatalf("expected a command format error, got: %v", err)
+	}
+	if formatErr.Command != "Assert equals" || formatErr.Line != 3 {
+		t.Errorf("wrong command or line in: %v", formatErr)
+	}
+	for _, expected := range []string{"missing property 'expected'", "property 'expectd' is not allowed"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q, got: %v", expected, err)
+		}
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	script, err := ParseScript([]byte("Print: Hello\n\nNo such command: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ExecuteScript(script, nil, false)
+	if err == nil || err.Error() != "line 3: Unknown command: No such command" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNonInteractivePrompts(t *testing.T) {
+	script, err := ParseScript([]byte(`
+Prompt object:
+  name:
+    description: What is your name?
+    default: World
+  greeting:
+    description: Greeting
+    default: Hello ${name}
+
+Expected output:
+  name: World
+  greeting: Hello World
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ExecuteScript(script, nil, true); err != nil {
+		t.Error(err)
+	}
+
+	script, err = ParseScript([]byte("Prompt: What is your name?\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ExecuteScript(script, nil, true)
+	if err == nil || !strings.Contains(err.Error(), "non-interactive mode") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestScriptInput(t *testing.T) {
+	script, err := ParseScript([]byte(`
+Script info:
+  input:
+    switch: Choose a or b
+    property-A:
+      condition:
+        item: ${switch}
+        equals: a
+      default: Ananas
+    property-B:
+      condition:
+        item: ${input.switch}
+        equals: b
+      default: Bologna
+
+Expected output:
+  switch: b
+  property-B: Bologna
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, param := range script.Metadata.Input {
+		names = append(names, param.Name)
+	}
+	if strings.Join(names, ",") != "switch,property-A,property-B" {
+		t.Errorf("input parameters not in order: %v", names)
+	}
+
+	if err := ExecuteScript(script, map[string]string{"switch": "b"}, true); err != nil {
+		t.Error(err)
+	}
+	err = ExecuteScript(script, nil, true)
+	if err == nil || err.Error() != "No value provided for: switch" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestScriptHelp(t *testing.T) {
+	script, err := ParseScript([]byte(`
+Script info:
+  description: A script with input parameters
+  input:
+    name: The name to greet
+    count:
+      description: How many times
+      type: number
+      short option: c
+      default: 1
+    api-token:
+      description: The token
+      secret: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	help, err := GetScriptHelp(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "A script with input parameters\n\n" +
+		"Input parameters:\n" +
+		"  --name        The name to greet\n" +
+		"  --count, -c   How many times\n" +
+		"  --api-token   The token\n"
+	if help != expected {
+		t.Errorf("Not equal:\n  Expected: %q\n  Actual:   %q", expected, help)
+	}
+
+	input, err := ParseInputArgs([]string{"--name", "World", "-c", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	script.Metadata.Input.ExpandShortOptions(input)
+	script.Commands = append(script.Commands, commands.Command{Name: "Expected output", Data: map[string]interface{}{
+		"name": "World", "count": 3, "api-token": "secret",
+	}})
+	input["api-token"] = "secret"
+	if err := ExecuteScript(script, input, true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExpectedConsoleOutput(t *testing.T) {
+	script, err := ParseScript([]byte(`
+Test case: Console output
+
+Answers:
+  What is your name?: Alice
+
+Prompt: What is your name?
+
+Print: Hello ${output}!
+
+Expected console output: |
+  ? What is your name? Alice
+  Hello Alice!
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ExecuteScript(script, nil, true); err != nil {
+		t.Error(err)
+	}
+
+	script, err = ParseScript([]byte(`
+Test case: Unexpected console output
+
+Print: |
+  one
+  two
+  three
+
+Expected console output: |
+  one
+  four
+  three
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ExecuteScript(script, nil, true)
+	expected := "Unexpected console output.\n    one\n  - four\n  + two\n    three"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Not equal:\n  Expected: %q\n  Actual:   %v", expected, err)
+	}
+}
+
+func TestVariableAssignmentsRunInOrder(t *testing.T) {
+	script, err := ParseScript([]byte(`
+${name}: World
+${greeting}: Hello ${name}
+Print: ${greeting}
+
+${name}: everyone
+Print: ${greeting}
+
+${greeting}: Goodbye ${name}
+Print: ${greeting}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := executeScript(script, nil, true, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	expected := "Hello World\nHello World\nGoodbye everyone\n"
+	if out.String() != expected {
+		t.Errorf("Not equal:\n  Expected: %q\n  Actual:   %q", expected, out.String())
+	}
+
+	// A variable is not known before the line that assigns it
+	script, err = ParseScript([]byte("${greeting}: Hello ${name}\n${name}: World\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = executeScript(script, nil, true, &strings.Builder{}, nil)
+	if err == nil || err.Error() != "line 1: error resolving variables in ${greeting}: Unknown variable ${name}" {
+		t.Errorf("Expected an error for the variable that is assigned later, got: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/commands/userinteraction"
+	"instacli/pkg/cli/commands/variables"
+	"instacli/pkg/cli/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InputVariable is the variable that holds all input parameters of a script
+const InputVariable = "input"
+
+// handleScriptInfo makes sure all input parameters of the script have a value. Parameters that were not given
+// take their default value or are asked for, in the order they are declared.
+// The input is returned as output.
+func handleScriptInfo(ctx *commands.ExecutionContext, data interface{}) (interface{}, error) {
+	if _, ok := data.(map[string]interface{}); !ok {
+		// Only a description
+		return nil, nil
+	}
+
+	var metadata ScriptMetadata
+	node := ctx.CommandNode()
+	if node == nil {
+		node = &yaml.Node{}
+		if err := node.Encode(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := node.Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("error parsing script info: %w", err)
+	}
+
+	params, err := metadata.Params(ctx.Types())
+	if err != nil {
+		return nil, err
+	}
+	return handleInput(ctx, params)
+}
+
+func handleInput(ctx *commands.ExecutionContext, params InputParams) (map[string]interface{}, error) {
+	input, ok := ctx.GetVar(InputVariable).(map[string]interface{})
+	if !ok {
+		input = make(map[string]interface{})
+		ctx.SetVar(InputVariable, input)
+	}
+
+	for _, param := range params {
+		// Given on the command line or set by the script, copy to a variable of its own
+		if value, ok := input[param.Name]; ok {
+			value, err := convertArg(ctx, param, value)
+			if err != nil {
+				return nil, err
+			}
+			input[param.Name] = value
+			ctx.SetVar(param.Name, value)
+			continue
+		}
+
+		// Conditions and defaults may refer to input parameters that were handled before
+		question := param.ParameterData
+		if err := resolveParameter(&question, ctx.Vars()); err != nil {
+			return nil, fmt.Errorf("error resolving variables in input parameter '%s': %w", param.Name, err)
+		}
+		valid, err := question.ConditionValid()
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			continue
+		}
+
+		var answer interface{}
+		switch {
+		case question.Default != nil:
+			answer = question.Default
+		case ctx.NonInteractive():
+			return nil, fmt.Errorf("No value provided for: %s", param.Name)
+		default:
+			if answer, err = question.Prompt(ctx, param.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		input[param.Name] = answer
+		ctx.SetVar(param.Name, answer)
+	}
+
+	return input, nil
+}
+
+// convertArg converts text from the command line to the type of the input parameter
+func convertArg(ctx *commands.ExecutionContext, param *InputParam, value interface{}) (interface{}, error) {
+	text, ok := value.(string)
+	if !ok || param.Type == nil {
+		return value, nil
+	}
+	def, err := ctx.Types().Resolve(param.Type)
+	if err != nil {
+		return nil, err
+	}
+	switch def.Base {
+	case types.Number:
+		if n, err := strconv.Atoi(text); err == nil {
+			return n, nil
+		}
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for %s: '%s' is not a number", param.Name, text)
+		}
+		return n, nil
+	case types.Boolean:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for %s: '%s' is not true or false", param.Name, text)
+		}
+		return b, nil
+	}
+	return value, nil
+}
+
+// resolveParameter resolves the variables in the parts of a parameter definition that may refer to other input
+func resolveParameter(p *userinteraction.ParameterData, vars map[string]interface{}) error {
+	if p.Condition != nil {
+		resolved, err := variables.ResolveVariablesRecursive(p.Condition, vars)
+		if err != nil {
+			return err
+		}
+		p.Condition, _ = resolved.(map[string]interface{})
+	}
+	if p.Default != nil {
+		resolved, err := variables.ResolveVariablesRecursive(p.Default, vars)
+		if err != nil {
+			return err
+		}
+		p.Default = resolved
+	}
+	if p.Enum != nil {
+		resolved, err := variables.ResolveVariablesRecursive(p.Enum, vars)
+		if err != nil {
+			return err
+		}
+		p.Enum, _ = resolved.([]interface{})
+	}
+	return nil
+}
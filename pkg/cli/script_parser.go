@@ -1,252 +1,278 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"reflect"
+	"io"
 	"strings"
 
 	"instacli/pkg/cli/commands"
-	"instacli/pkg/cli/commands/testing"
-	"instacli/pkg/cli/commands/variables"
-
-	"bytes"
+	"instacli/pkg/cli/commands/userinteraction"
+	"instacli/pkg/cli/types"
+	"instacli/pkg/values"
 
 	"gopkg.in/yaml.v3"
 )
 
+// ScriptInfoCommand is the command that holds the description and input definition of a script
+const ScriptInfoCommand = "Script info"
+
 // ScriptMetadata represents the metadata section of a script
 type ScriptMetadata struct {
-	Description string                `yaml:"description"`
-	Input       map[string]InputParam `yaml:"input"`
-}
-
-// InputParam represents an input parameter definition
-type InputParam struct {
-	Description string `yaml:"description"`
-	Default     string `yaml:"default,omitempty"`
+	Description string      `yaml:"description"`
+	Input       InputParams `yaml:"input"`
+	// InputTypeSpec defines the input as a type instead of a list of parameters
+	InputTypeSpec *types.Type `yaml:"input type"`
+	// Hidden scripts are not listed as commands of their directory
+	Hidden bool `yaml:"hidden"`
+	// InstacliSpec is the version of the Instacli spec that the script is written for
+	InstacliSpec string `yaml:"instacli-spec"`
 }
 
-// ScriptCommand represents a single command in the script
-type ScriptCommand struct {
-	Print          string                 `yaml:"Print,omitempty"`
-	AssertEquals   map[string]interface{} `yaml:"Assert equals,omitempty"`
-	AssertThat     map[string]interface{} `yaml:"Assert that,omitempty"`
-	Output         interface{}            `yaml:"Output,omitempty"`
-	ExpectedOutput interface{}            `yaml:"Expected output,omitempty"`
-	As             string                 `yaml:"As,omitempty"`
-	TestCase       string                 `yaml:"Test case,omitempty"`
-	VarAssign      *VarAssignment         `yaml:"-"`
+// UnmarshalYAML reads the script info from either a description or a full definition
+func (m *ScriptMetadata) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*m = ScriptMetadata{Description: node.Value}
+		return nil
+	}
+	type plain ScriptMetadata
+	return node.Decode((*plain)(m))
 }
 
-type VarAssignment struct {
-	Name  string
-	Value interface{}
+// Params returns the input parameters of the script in the order they were declared. If the input is given as a
+// type, there is a parameter for each of its properties.
+func (m *ScriptMetadata) Params(registry *types.Registry) (InputParams, error) {
+	if m.InputTypeSpec == nil {
+		return m.Input, nil
+	}
+	def, err := registry.Resolve(m.InputTypeSpec)
+	if err != nil {
+		return nil, fmt.Errorf("error in input type: %w", err)
+	}
+	params := make(InputParams, 0, len(def.Properties))
+	for _, prop := range def.Properties {
+		params = append(params, &InputParam{
+			Name: prop.Name,
+			ParameterData: userinteraction.ParameterData{
+				Description: prop.Description,
+				Default:     prop.Default,
+				Type:        prop.Type,
+			},
+		})
+	}
+	return params, nil
 }
 
-// ParsedScript represents a parsed Instacli script
-type ParsedScript struct {
-	Metadata ScriptMetadata
-	Commands []ScriptCommand
+// InputParam represents an input parameter definition. It is asked for like a Prompt if it is not given.
+type InputParam struct {
+	Name                          string `yaml:"-"`
+	userinteraction.ParameterData `yaml:",inline"`
 }
 
-// ParseScript parses a script file into a ParsedScript struct
-func ParseScript(data []byte) (*ParsedScript, error) {
-	content := string(data)
-	script := &ParsedScript{
-		Metadata: ScriptMetadata{
-			Input: make(map[string]InputParam),
-		},
+// UnmarshalYAML reads an input parameter from either a description or a full definition
+func (p *InputParam) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*p = InputParam{ParameterData: userinteraction.ParameterData{Description: node.Value}}
+		return nil
 	}
+	type plain InputParam
+	return node.Decode((*plain)(p))
+}
 
-	// varAssignRegex := regexp.MustCompile(`(?m)^\$\{([^}]+)}:\s*(.*)$`)
-
-	// Check if the script has metadata (contains "---" separator)
-	if strings.Contains(content, "---\n") {
-		parts := strings.SplitN(content, "---\n", 2)
-
-		// Parse metadata section
-		if err := yaml.Unmarshal([]byte(parts[0]), &script.Metadata); err != nil {
-			return nil, fmt.Errorf("error parsing script info: %w", err)
-		}
+// InputParams holds the input parameters of a script in the order they were declared
+type InputParams []*InputParam
 
-		// Parse commands section
-		if err := yaml.Unmarshal([]byte(parts[1]), &script.Commands); err != nil {
-			return nil, fmt.Errorf("error parsing commands: %w", err)
+// ExpandShortOptions renames the input that was given with the short option of a parameter to the parameter name
+func (p InputParams) ExpandShortOptions(input map[string]string) {
+	for _, param := range p {
+		if param.ShortOption == "" {
+			continue
 		}
-	} else {
-		// Use yaml.Decoder to parse multiple YAML documents or multiple top-level keys
-		dec := yaml.NewDecoder(bytes.NewReader(data))
-		for {
-			var m map[string]interface{}
-			if err := dec.Decode(&m); err != nil {
-				if err.Error() == "EOF" {
-					break
-				}
-				return nil, fmt.Errorf("error decoding YAML: %w", err)
-			}
-			if len(m) == 0 {
-				continue
-			}
-			// For each top-level key, create a ScriptCommand
-			for k, v := range m {
-				cmd := ScriptCommand{}
-				switch k {
-				case "Print":
-					cmd.Print, _ = v.(string)
-				case "Assert equals":
-					if mm, ok := v.(map[string]interface{}); ok {
-						cmd.AssertEquals = mm
-					}
-				case "Assert that":
-					if mm, ok := v.(map[string]interface{}); ok {
-						cmd.AssertThat = mm
-					}
-				case "Output":
-					cmd.Output = v
-				case "Expected output":
-					cmd.ExpectedOutput = v
-				case "As":
-					cmd.As, _ = v.(string)
-				case "Test case":
-					cmd.TestCase, _ = v.(string)
-				default:
-					// Try variable assignment
-					if strings.HasPrefix(k, "${") && strings.HasSuffix(k, "}") {
-						cmd.VarAssign = &VarAssignment{Name: k[2 : len(k)-1], Value: v}
-					}
-				}
-				script.Commands = append(script.Commands, cmd)
+		if value, ok := input[param.ShortOption]; ok {
+			delete(input, param.ShortOption)
+			if _, ok := input[param.Name]; !ok {
+				input[param.Name] = value
 			}
 		}
 	}
+}
 
-	return script, nil
+// UnmarshalYAML reads the input parameters, keeping the order of declaration
+func (p *InputParams) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: input must be an object", node.Line)
+	}
+	params := make(InputParams, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		param := &InputParam{}
+		if err := node.Content[i+1].Decode(param); err != nil {
+			return err
+		}
+		param.Name = node.Content[i].Value
+		params = append(params, param)
+	}
+	*p = params
+	return nil
 }
 
-// ExecuteScript runs the script with the given input parameters
-func ExecuteScript(script *ParsedScript, input map[string]string) error {
-	ctx := commands.NewExecutionContext()
-
-	// First pass: fixed-point iteration for variable assignments
-	maxIterations := 10
-	for i := 0; i < maxIterations; i++ {
-		progress := false
-		for _, cmd := range script.Commands {
-			if cmd.VarAssign != nil {
-				val := cmd.VarAssign.Value
-				vars := ctx.Vars()
-				_, alreadySet := vars[cmd.VarAssign.Name]
-				resolved, err := variables.ResolveVariablesRecursive(val, vars)
-				if err == nil {
-					if !alreadySet || !reflect.DeepEqual(vars[cmd.VarAssign.Name], resolved) {
-						ctx.SetVar(cmd.VarAssign.Name, resolved)
-						progress = true
-					}
-				}
-			}
+// InputType returns the input of the script as an object type.
+// Input parameters are text unless they have a type, and the ones without a default value are required.
+func (m *ScriptMetadata) InputType() *types.Type {
+	if m.InputTypeSpec != nil {
+		return m.InputTypeSpec
+	}
+	t := &types.Type{Base: types.Object, Properties: types.ObjectProperties{}}
+	for _, param := range m.Input {
+		prop := &types.Property{
+			Name:        param.Name,
+			Description: param.Description,
+			Optional:    param.Default != nil,
+			Default:     param.Default,
+			Type:        param.Type,
 		}
-		if !progress {
-			break
+		if prop.Type == nil {
+			prop.Type = types.Reference(types.String)
 		}
+		t.Properties = append(t.Properties, prop)
 	}
+	return t
+}
 
-	// Second pass: process all other commands (skip only true variable assignments)
-	for _, cmd := range script.Commands {
-		if cmd.TestCase != "" {
-			continue
+// ParsedScript represents a parsed Instacli script
+type ParsedScript struct {
+	Metadata ScriptMetadata
+	Commands []commands.Command
+	// File is the path of the script, if it was read from a file
+	File string
+	// Dir is the directory of the script file, used to resolve files relative to the script
+	Dir string
+	// Types holds the named types available to the script, for example from types.yaml next to it
+	Types *types.Registry
+	// CaptureOutput records the console output from the start, so it can be checked without a Test case command
+	CaptureOutput bool
+	// Strict makes a reference to an unknown variable an error
+	Strict bool
+	// TempDir is the temp dir that the script runs in, and also the directory where it finds other scripts and files.
+	// The sections of a Markdown document share one, so a file that a section writes is found by the next ones. The
+	// script makes a temp dir of its own if it is empty.
+	TempDir string
+	// Context stops the script when it is done, like on Ctrl-C. The script runs until the end if it is nil.
+	Context context.Context
+}
+
+// ParseScript parses a script file into a ParsedScript struct.
+// A script consists of one or more YAML documents. The keys of each document are the commands,
+// which are kept in order. The same command may appear more than once.
+// Commands run in this order, variable assignments too: a variable is known from the line that assigns it.
+func ParseScript(data []byte) (*ParsedScript, error) {
+	script := &ParsedScript{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error decoding YAML: %w", err)
 		}
-		if cmd.VarAssign != nil {
-			// Already processed in first pass
+		if len(doc.Content) == 0 {
 			continue
 		}
-		if cmd.As != "" {
-			asCmd, err := variables.NewAsCommand(cmd.As)
-			if err != nil {
-				return fmt.Errorf("error creating As command: %w", err)
-			}
-			if err := asCmd.Execute(ctx); err != nil {
-				return fmt.Errorf("error executing As command: %w", err)
-			}
+		root := doc.Content[0]
+		if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
 			continue
 		}
-		vars := ctx.Vars()
-		if cmd.Print != "" {
-			output, _ := variables.ResolveVariablesInText(cmd.Print, vars)
-			fmt.Println(output)
-			ctx.SetOutput(output)
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("line %d: a script must consist of commands, like 'Print: Hello'", root.Line)
 		}
-		if cmd.Output != nil {
-			val, err := variables.ResolveVariablesRecursive(cmd.Output, vars)
-			if err != nil {
-				return fmt.Errorf("error resolving variables in Output: %w", err)
-			}
-			outputCmd := variables.NewOutputCommand(val)
-			if err := outputCmd.Execute(ctx); err != nil {
-				return fmt.Errorf("error executing Output command: %w", err)
-			}
+
+		cmds, err := ParseCommands(root)
+		if err != nil {
+			return nil, err
 		}
-		if cmd.ExpectedOutput != nil {
-			expected, err := variables.ResolveVariablesRecursive(cmd.ExpectedOutput, vars)
-			if err != nil {
-				return fmt.Errorf("error resolving variables in Expected output: %w", err)
-			}
-			actual := ctx.GetOutput()
-			actualYAML, _ := yaml.Marshal(actual)
-			expectedYAML, _ := yaml.Marshal(expected)
-			actualStr := strings.TrimSpace(string(actualYAML))
-			expectedStr := strings.TrimSpace(string(expectedYAML))
-			if actualStr != expectedStr {
-				return fmt.Errorf("Unexpected output.\n  Expected: %s\n  Actual:   %s", expectedStr, actualStr)
+		for _, cmd := range cmds {
+			if cmd.Name == ScriptInfoCommand {
+				if err := cmd.Node.Decode(&script.Metadata); err != nil {
+					return nil, fmt.Errorf("line %d: error parsing script info: %w", cmd.Node.Line, err)
+				}
 			}
 		}
-		if cmd.AssertEquals != nil {
-			resolved, err := variables.ResolveVariablesRecursive(cmd.AssertEquals, vars)
-			if err != nil {
-				return fmt.Errorf("error resolving variables in Assert equals: %w", err)
-			}
-			assertCmd, err := testing.NewAssertEquals(resolved.(map[string]interface{}))
+		script.Commands = append(script.Commands, cmds...)
+	}
+
+	return script, nil
+}
+
+// ParseCommands reads the commands in a YAML object, in order. A list of objects is read as the commands of
+// each object after the other.
+func ParseCommands(node *yaml.Node) ([]commands.Command, error) {
+	if node.Kind == yaml.SequenceNode {
+		var cmds []commands.Command
+		for _, item := range node.Content {
+			itemCommands, err := ParseCommands(item)
 			if err != nil {
-				return fmt.Errorf("error creating Assert equals command: %w", err)
-			}
-			// Trim whitespace for actual and expected if they are strings
-			if a, ok := assertCmd.Actual.(string); ok {
-				assertCmd.Actual = strings.TrimSpace(a)
-			}
-			if e, ok := assertCmd.Expected.(string); ok {
-				assertCmd.Expected = strings.TrimSpace(e)
-			}
-			if err := assertCmd.Execute(); err != nil {
-				return fmt.Errorf("assertion failed: %w", err)
+				return nil, err
 			}
+			cmds = append(cmds, itemCommands...)
 		}
-		if cmd.AssertThat != nil {
-			resolved, err := variables.ResolveVariablesRecursive(cmd.AssertThat, vars)
-			if err != nil {
-				return fmt.Errorf("error resolving variables in Assert that: %w", err)
-			}
-			assertCmd, err := testing.NewAssertThat(resolved.(map[string]interface{}))
-			if err != nil {
-				return fmt.Errorf("error creating Assert that command: %w", err)
-			}
-			if err := assertCmd.Execute(); err != nil {
-				return fmt.Errorf("assertion failed: %w", err)
-			}
+		return cmds, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected commands, like 'Print: Hello'", node.Line)
+	}
+
+	var cmds []commands.Command
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		commandData, err := values.FromNode(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: error parsing '%s': %w", value.Line, key.Value, err)
 		}
+		cmds = append(cmds, commands.Command{
+			Name: key.Value,
+			Data: commandData,
+			Line: key.Line,
+			Node: value,
+		})
 	}
-	return nil
+	return cmds, nil
 }
 
-// GetScriptHelp returns the help text for the script
-func GetScriptHelp(script *ParsedScript) string {
+// GetScriptHelp returns the help text for the script: the description and the input parameters in the order they
+// were declared
+func GetScriptHelp(script *ParsedScript) (string, error) {
+	registry := script.Types
+	if registry == nil {
+		registry = types.NewRegistry()
+	}
+	params, err := script.Metadata.Params(registry)
+	if err != nil {
+		return "", err
+	}
+
 	var help strings.Builder
-	help.WriteString(script.Metadata.Description + "\n\n")
+	if script.Metadata.Description != "" {
+		help.WriteString(script.Metadata.Description + "\n")
+	}
+	if len(params) == 0 {
+		return help.String(), nil
+	}
 
-	if len(script.Metadata.Input) > 0 {
-		help.WriteString("Options:\n")
-		for name, param := range script.Metadata.Input {
-			help.WriteString(fmt.Sprintf("  --%s   %s\n", name, param.Description))
+	keys := make([]string, len(params))
+	width := 10
+	for i, param := range params {
+		keys[i] = "--" + param.Name
+		if param.ShortOption != "" {
+			keys[i] += ", -" + param.ShortOption
 		}
+		width = max(width, len(keys[i]))
 	}
-
-	return help.String()
+	help.WriteString("\nInput parameters:\n")
+	for i, param := range params {
+		help.WriteString(fmt.Sprintf("  %-*s   %s\n", width, keys[i], strings.TrimSpace(param.Description)))
+	}
+	return help.String(), nil
 }
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestReport writes test results in a machine-readable format
+type TestReport func(w io.Writer, results []*TestResult) error
+
+// TestReports holds the available report formats by name
+var TestReports = map[string]TestReport{
+	"junit": WriteJUnitReport,
+	"tap":   WriteTAPReport,
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Time      string           `xml:"time,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+
+	duration time.Duration
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Details string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the results as JUnit XML, with a test suite for each file. The code examples of a file
+// are in a test suite of their own.
+func WriteJUnitReport(w io.Writer, results []*TestResult) error {
+	report := &junitTestSuites{}
+	suites := make(map[string]*junitTestSuite)
+	var total time.Duration
+
+	for _, result := range results {
+		suiteName := result.File
+		if result.Example {
+			suiteName += " (code examples)"
+		}
+		suite, ok := suites[suiteName]
+		if !ok {
+			suite = &junitTestSuite{Name: suiteName}
+			suites[suiteName] = suite
+			report.Suites = append(report.Suites, suite)
+		}
+
+		testCase := &junitTestCase{
+			Name:      result.Name,
+			ClassName: suiteName,
+			File:      result.File,
+			Line:      result.Line,
+			Time:      junitSeconds(result.Duration),
+			SystemOut: result.Output,
+		}
+		if !result.Passed() {
+			message := result.Err.Error()
+			firstLine, _, _ := strings.Cut(message, "\n")
+			testCase.Failure = &junitFailure{Message: firstLine, Details: message}
+			suite.Failures++
+			report.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+		suite.duration += result.Duration
+		report.Tests++
+		total += result.Duration
+	}
+
+	for _, suite := range report.Suites {
+		suite.Time = junitSeconds(suite.duration)
+	}
+	report.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// tapDiagnostics holds the details of a failed test case, in the order they are shown
+type tapDiagnostics struct {
+	Message    string  `yaml:"message"`
+	File       string  `yaml:"file"`
+	Line       int     `yaml:"line,omitempty"`
+	DurationMS float64 `yaml:"duration_ms"`
+	Output     string  `yaml:"output,omitempty"`
+}
+
+// WriteTAPReport writes the results in the Test Anything Protocol, version 13.
+// Failed test cases have the failure message as YAML diagnostics.
+func WriteTAPReport(w io.Writer, results []*TestResult) error {
+	var out strings.Builder
+	out.WriteString("TAP version 13\n")
+	fmt.Fprintf(&out, "1..%d\n", len(results))
+
+	for i, result := range results {
+		status := "ok"
+		if !result.Passed() {
+			status = "not ok"
+		}
+		fmt.Fprintf(&out, "%s %d - %s > %s # time=%s\n", status, i+1, tapEscape(result.File), tapEscape(displayName(result.TestCase)), formatDuration(result.Duration))
+		if result.Passed() {
+			continue
+		}
+
+		diagnostics, err := yaml.Marshal(&tapDiagnostics{
+			Message:    result.Err.Error(),
+			File:       result.File,
+			Line:       result.Line,
+			DurationMS: float64(result.Duration.Microseconds()) / 1000,
+			Output:     result.Output,
+		})
+		if err != nil {
+			return err
+		}
+		out.WriteString("  ---\n")
+		for _, line := range strings.Split(strings.TrimRight(string(diagnostics), "\n"), "\n") {
+			out.WriteString("  " + line + "\n")
+		}
+		out.WriteString("  ...\n")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// tapEscape escapes characters that have a meaning in a TAP test line
+func tapEscape(name string) string {
+	return strings.NewReplacer(`\`, `\\`, "#", `\#`, "\n", " ").Replace(name)
+}
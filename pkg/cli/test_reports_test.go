@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reportResults() []*TestResult {
+	return []*TestResult{
+		{TestCase: &TestCase{Name: "Passing", File: "a.cli", Line: 1}, Duration: 1500 * time.Microsecond},
+		{TestCase: &TestCase{Name: "Failing #1", File: "a.cli", Line: 7}, Duration: 2 * time.Millisecond,
+			Err: errors.New("Unexpected output.\n  Expected: 2\n  Actual:   1"), Output: "hi\n"},
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	var out strings.Builder
+	if err := WriteJUnitReport(&out, reportResults()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2" failures="1" time="0.004">
+  <testsuite name="a.cli" tests="2" failures="1" time="0.004">
+    <testcase name="Passing" classname="a.cli" file="a.cli" line="1" time="0.002"></testcase>
+    <testcase name="Failing #1" classname="a.cli" file="a.cli" line="7" time="0.002">
+      <failure message="Unexpected output.">Unexpected output.&#xA;  Expected: 2&#xA;  Actual:   1</failure>
+      <system-out>hi&#xA;</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if out.String() != expected {
+		t.Errorf("Not equal:\n  Expected: %s\n  Actual:   %s", expected, out.String())
+	}
+}
+
+func TestTAPReport(t *testing.T) {
+	var out strings.Builder
+	if err := WriteTAPReport(&out, reportResults()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `TAP version 13
+1..2
+ok 1 - a.cli > Passing # time=1.5ms
+not ok 2 - a.cli > Failing \#1 # time=2.0ms
+  ---
+  message: |-
+      Unexpected output.
+        Expected: 2
+        Actual:   1
+  file: a.cli
+  line: 7
+  duration_ms: 2
+  output: |
+      hi
+  ...
+`
+	if out.String() != expected {
+		t.Errorf("Not equal:\n  Expected: %s\n  Actual:   %s", expected, out.String())
+	}
+}
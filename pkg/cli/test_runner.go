@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"instacli/pkg/cli/commands"
+	"instacli/pkg/cli/types"
+)
+
+// TestCaseCommand is the command that starts a test case
+const TestCaseCommand = "Test case"
+
+// CodeExampleCommand is the command that starts an example in a Markdown document
+const CodeExampleCommand = "Code example"
+
+// ScriptExtension is the file extension of Instacli scripts
+const ScriptExtension = ".cli"
+
+// TestCase is a single test case or code example from a script
+type TestCase struct {
+	Name string
+	// File is the script the test case comes from
+	File string
+	// Line is the line of the Test case or Code example command
+	Line int
+	// Script holds the commands of the test case only
+	Script *ParsedScript
+	// Example tells that this is a code example from the documentation rather than a test case
+	Example bool
+}
+
+// Kind returns the command that starts this kind of test case, "Test case" or "Code example"
+func (tc *TestCase) Kind() string {
+	if tc.Example {
+		return CodeExampleCommand
+	}
+	return TestCaseCommand
+}
+
+// TestResult is the outcome of running a test case
+type TestResult struct {
+	*TestCase
+	// Err is the reason the test case failed, or nil if it passed
+	Err      error
+	Duration time.Duration
+	// Output is what the test case printed to the console
+	Output string
+}
+
+// Passed tells if the test case ran without errors
+func (r *TestResult) Passed() bool {
+	return r.Err == nil
+}
+
+// SplitTestCases splits a script into test cases. Each test case starts with a Test case or Code example command
+// and runs up to the next one. Commands before the first test case are ignored.
+func SplitTestCases(script *ParsedScript, file string) []*TestCase {
+	var testCases []*TestCase
+	var current *TestCase
+	for _, cmd := range script.Commands {
+		if cmd.Name == TestCaseCommand || cmd.Name == CodeExampleCommand {
+			name, ok := cmd.Data.(string)
+			if !ok {
+				name = cmd.Name
+			}
+			current = &TestCase{
+				Name:    name,
+				File:    file,
+				Line:    cmd.Line,
+				Script:  &ParsedScript{Metadata: script.Metadata, File: script.File, Dir: script.Dir, Types: script.Types},
+				Example: cmd.Name == CodeExampleCommand,
+			}
+			testCases = append(testCases, current)
+		}
+		if current != nil {
+			current.Script.Commands = append(current.Script.Commands, cmd)
+		}
+	}
+	return testCases
+}
+
+// RunTestCase runs a test case in a context of its own, without user interaction. The output is recorded
+// instead of printed.
+func RunTestCase(testCase *TestCase) *TestResult {
+	var output bytes.Buffer
+	start := time.Now()
+	ctx, err := newScriptContext(testCase.Script, nil, true, commands.NewConsole(&output))
+	if err == nil {
+		err = runCommands(ctx, testCase.Script.Commands)
+		if testCase.Script.TempDir == "" {
+			os.RemoveAll(ctx.TempDir())
+		}
+	}
+
+	return &TestResult{TestCase: testCase, Err: err, Duration: time.Since(start), Output: output.String()}
+}
+
+// RemoveTempDirs removes the temp dir that the test cases of a Markdown document share
+func RemoveTempDirs(testCases []*TestCase) {
+	for _, testCase := range testCases {
+		if testCase.Script.TempDir != "" {
+			os.RemoveAll(testCase.Script.TempDir)
+		}
+	}
+}
+
+// FindTestFiles returns the scripts and Markdown specification documents in a directory and its subdirectories,
+// or the file itself if path is a file
+func FindTestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error accessing path: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ScriptExtension) || strings.HasSuffix(entry.Name(), MarkdownExtension)) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// TestRunner runs the test cases in script files and reports the results
+type TestRunner struct {
+	// Filter selects the test cases to run by name. All test cases run if it is empty.
+	Filter string
+	// SkipExamples tells to run test cases only, and not the code examples
+	SkipExamples bool
+	// Strict makes a reference to an unknown variable an error in all test cases
+	Strict bool
+	// Context stops the test cases when it is done, like on Ctrl-C
+	Context context.Context
+	// Out is where the results are reported
+	Out io.Writer
+}
+
+// Run runs all test cases that match the filter in the files under path, reporting each result as it comes in.
+// A file that can not be read or parsed is reported as a failed test case.
+func (r *TestRunner) Run(path string) ([]*TestResult, error) {
+	files, err := FindTestFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*TestResult
+	for _, file := range files {
+		testCases, err := LoadTestCases(file)
+		if err != nil {
+			results = append(results, r.report(&TestResult{TestCase: &TestCase{Name: filepath.Base(file), File: file}, Err: err}))
+			continue
+		}
+		for _, testCase := range testCases {
+			if !r.matches(testCase) {
+				continue
+			}
+			testCase.Script.Strict = r.Strict
+			testCase.Script.Context = r.Context
+			results = append(results, r.report(RunTestCase(testCase)))
+		}
+		RemoveTempDirs(testCases)
+	}
+
+	r.summary(results)
+	return results, nil
+}
+
+// LoadTestCases reads the test cases from a script, or the code examples from a Markdown document
+func LoadTestCases(file string) ([]*TestCase, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading script file: %w", err)
+	}
+
+	dir := filepath.Dir(file)
+	registry := types.NewRegistry()
+	if err := registry.LoadDir(dir); err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(file, ".md") {
+		return markdownTestCases(data, file, dir, registry)
+	}
+
+	script, err := ParseScript(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing script: %w", err)
+	}
+	script.File = file
+	script.Dir = dir
+	script.Types = registry
+	return SplitTestCases(script, file), nil
+}
+
+// markdownTestCases turns each section of a Markdown document that has Instacli code into a test case. The test cases
+// share a temp dir, that is also their script dir, so the files that a section writes are found by the next ones.
+// Remove it with RemoveTempDirs.
+func markdownTestCases(data []byte, file string, dir string, registry *types.Registry) ([]*TestCase, error) {
+	var testCases []*TestCase
+	tempDir, err := os.MkdirTemp("", "instacli-")
+	if err != nil {
+		return nil, fmt.Errorf("can not create temp dir: %w", err)
+	}
+	for _, section := range SplitMarkdownSections(ScanMarkdown(data)) {
+		cmds, err := section.Commands()
+		if err != nil {
+			os.RemoveAll(tempDir)
+			return nil, err
+		}
+		if len(cmds) == 0 {
+			continue
+		}
+		testCases = append(testCases, &TestCase{
+			Name:    sectionName(section, cmds),
+			File:    file,
+			Line:    section.Line,
+			Script:  &ParsedScript{Commands: cmds, File: file, Dir: dir, Types: registry, CaptureOutput: true, TempDir: tempDir},
+			Example: hasCodeExample(cmds),
+		})
+	}
+	if len(testCases) == 0 {
+		os.RemoveAll(tempDir)
+	}
+	return testCases, nil
+}
+
+// sectionName names a test case from a Markdown section after its header, or its code example
+func sectionName(section *MarkdownSection, cmds []commands.Command) string {
+	if section.Title != "" {
+		return section.Title
+	}
+	for _, cmd := range cmds {
+		if name, ok := cmd.Data.(string); ok && cmd.Name == CodeExampleCommand {
+			return name
+		}
+	}
+	return CodeExampleCommand
+}
+
+func hasCodeExample(cmds []commands.Command) bool {
+	for _, cmd := range cmds {
+		if cmd.Name == CodeExampleCommand {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *TestRunner) matches(testCase *TestCase) bool {
+	if r.SkipExamples && testCase.Example {
+		return false
+	}
+	return r.Filter == "" || strings.Contains(strings.ToLower(testCase.Name), strings.ToLower(r.Filter))
+}
+
+func (r *TestRunner) report(result *TestResult) *TestResult {
+	status := "PASS"
+	if !result.Passed() {
+		status = "FAIL"
+	}
+	fmt.Fprintf(r.Out, "%s  %s > %s (%s)\n", status, result.File, displayName(result.TestCase), formatDuration(result.Duration))
+	if !result.Passed() {
+		fmt.Fprintf(r.Out, "      %s\n", strings.ReplaceAll(result.Err.Error(), "\n", "\n      "))
+	}
+	return result
+}
+
+// displayName returns the name of a test case as it is reported. Code examples are marked as such.
+func displayName(testCase *TestCase) string {
+	if testCase.Example {
+		return CodeExampleCommand + ": " + testCase.Name
+	}
+	return testCase.Name
+}
+
+func (r *TestRunner) summary(results []*TestResult) {
+	passed, examples, examplesPassed := 0, 0, 0
+	var total time.Duration
+	for _, result := range results {
+		if result.Passed() {
+			passed++
+		}
+		if result.Example {
+			examples++
+			if result.Passed() {
+				examplesPassed++
+			}
+		}
+		total += result.Duration
+	}
+	fmt.Fprintf(r.Out, "\n%d passed, %d failed (%s)\n", passed, len(results)-passed, formatDuration(total))
+	if examples > 0 {
+		fmt.Fprintf(r.Out, "  Test cases:    %d passed, %d failed\n", passed-examplesPassed, len(results)-examples-(passed-examplesPassed))
+		fmt.Fprintf(r.Out, "  Code examples: %d passed, %d failed\n", examplesPassed, examples-examplesPassed)
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d.Microseconds())/1000)
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTestRunner(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"one.cli": `
+Print: Not part of a test case
+
+---
+Test case: Passing test
+
+Output: one
+
+Expected output: one
+
+---
+Test case: Failing test
+
+Output: two
+
+Expected output: one
+`,
+		"sub/two.cli": `
+Test case: Another passing test
+
+Output: two
+
+Expected output: two
+`,
+		"sub/not a script.txt": "Test case: Not run",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out strings.Builder
+	results, err := (&TestRunner{Out: &out}).Run(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, result := range results {
+		actual = append(actual, result.Name+": "+map[bool]string{true: "passed", false: "failed"}[result.Passed()])
+	}
+	expected := "Passing test: passed, Failing test: failed, Another passing test: passed"
+	if strings.Join(actual, ", ") != expected {
+		t.Errorf("Not equal:\n  Expected: %s\n  Actual:   %s", expected, strings.Join(actual, ", "))
+	}
+	if !strings.Contains(out.String(), "2 passed, 1 failed") {
+		t.Errorf("missing summary in:\n%s", out.String())
+	}
+
+	results, err = (&TestRunner{Filter: "another", Out: &out}).Run(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "Another passing test" {
+		t.Errorf("filter did not select the right test case: %v", results)
+	}
+}
+
+func TestCodeExamples(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+Code example: First example
+
+${name}: Alice
+
+Print: Hello ${name}
+
+Expected console output: Hello Alice
+
+---
+Code example: Variables of earlier examples are not visible
+
+Print: Hello ${name}
+
+---
+Test case: A test case
+
+Print: Hello
+
+Expected console output: Hello
+`
+	if err := os.WriteFile(filepath.Join(dir, "examples.cli"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testCases, err := LoadTestCases(filepath.Join(dir, "examples.cli"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each example runs on its own, so the second one does not see the variable of the first one
+	if err := RunTestCase(testCases[1]).Err; err == nil || !strings.Contains(err.Error(), "name") {
+		t.Errorf("Expected an error on the unknown variable, got: %v", err)
+	}
+
+	// In a Markdown section, the example sees the variables of the hidden code before it
+	document := filepath.Join(t.TempDir(), "input.spec.md")
+	markdown := "## Greeting\n\n<!-- yaml instacli\n${input}:\n  greeting: Hi\n-->\n\n" +
+		"```yaml instacli\nCode example: Greeting\n\nScript info:\n  input:\n    greeting: The greeting\n\nPrint: ${greeting}\n```\n"
+	if err := os.WriteFile(document, []byte(markdown), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sections, err := LoadTestCases(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveTempDirs(sections)
+	if result := RunTestCase(sections[0]); result.Err != nil || result.Output != "Hi\n" {
+		t.Errorf("Expected the example to use the hidden input, got: %v %q", result.Err, result.Output)
+	}
+
+	var out strings.Builder
+	results, err := (&TestRunner{Out: &out}).Run(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || !results[0].Example || results[2].Example {
+		t.Fatalf("Expected two code examples and a test case, got: %v", results)
+	}
+	if !strings.Contains(out.String(), "PASS  "+filepath.Join(dir, "examples.cli")+" > Code example: First example") ||
+		!strings.Contains(out.String(), "Code examples: 1 passed, 1 failed") {
+		t.Errorf("Code examples are not reported separately:\n%s", out.String())
+	}
+
+	results, err = (&TestRunner{SkipExamples: true, Out: &out}).Run(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "A test case" {
+		t.Errorf("Code examples were not skipped: %v", results)
+	}
+}
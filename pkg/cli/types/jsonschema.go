@@ -0,0 +1,248 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// JSONSchemaDialect is the JSON Schema version that types are converted to
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// ToJSONSchema converts a type to JSON Schema. Named types that are not base types end up in $defs
+// and are referred to with $ref, so recursive types are supported.
+func (r *Registry) ToJSONSchema(t *Type) (map[string]interface{}, error) {
+	c := &schemaConverter{registry: r, defs: map[string]interface{}{}}
+	schema, err := c.convert(t)
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = JSONSchemaDialect
+	if len(c.defs) > 0 {
+		schema["$defs"] = c.defs
+	}
+	return schema, nil
+}
+
+type schemaConverter struct {
+	registry *Registry
+	defs     map[string]interface{}
+}
+
+func (c *schemaConverter) convert(t *Type) (map[string]interface{}, error) {
+	if t.Name != "" && !isBaseType(t.Name) {
+		if _, done := c.defs[t.Name]; !done {
+			found := c.registry.Get(t.Name)
+			if found == nil {
+				return nil, fmt.Errorf("Type not found: %s", t.Name)
+			}
+			// Reserve the name before converting, in case the type refers to itself
+			c.defs[t.Name] = nil
+			def, err := c.convert(found)
+			if err != nil {
+				return nil, err
+			}
+			c.defs[t.Name] = def
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + url.PathEscape(pointerEscaper.Replace(t.Name))}, nil
+	}
+
+	def, err := c.registry.Resolve(t)
+	if err != nil {
+		return nil, err
+	}
+	base, err := baseOf(def)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := map[string]interface{}{"type": base}
+	switch base {
+	case Object:
+		if def.Properties == nil {
+			break
+		}
+		properties := map[string]interface{}{}
+		var required []interface{}
+		for _, prop := range def.Properties {
+			propSchema := map[string]interface{}{}
+			if prop.Type != nil {
+				if propSchema, err = c.convert(prop.Type); err != nil {
+					return nil, err
+				}
+			}
+			if prop.Description != "" {
+				propSchema["description"] = prop.Description
+			}
+			if prop.Default != nil {
+				propSchema["default"] = prop.Default
+			}
+			properties[prop.Name] = propSchema
+			if !prop.Optional {
+				required = append(required, prop.Name)
+			}
+		}
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	case Array:
+		if def.ListOf != nil {
+			items, err := c.convert(def.ListOf)
+			if err != nil {
+				return nil, err
+			}
+			schema["items"] = items
+		}
+	}
+	return schema, nil
+}
+
+// pointerEscaper escapes a name for a JSON pointer, and pointerUnescaper reads it back
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// FromJSONSchema converts a JSON Schema to a type. Definitions in $defs or definitions are registered
+// as named types, so references to them become type references.
+// Only the keywords that have an equivalent in types are supported.
+func (r *Registry) FromJSONSchema(schema map[string]interface{}) (*Type, error) {
+	for _, keyword := range []string{"$defs", "definitions"} {
+		defs, _ := schema[keyword].(map[string]interface{})
+		for name, def := range defs {
+			defSchema, ok := def.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("definition '%s' must be an object", name)
+			}
+			t, err := fromSchema(defSchema, "#/"+keyword+"/"+name)
+			if err != nil {
+				return nil, err
+			}
+			r.Register(name, t)
+		}
+	}
+	return fromSchema(schema, "#")
+}
+
+// schemaKeywords are the JSON Schema keywords that can be converted to types, or that can be ignored safely
+var schemaKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "$defs": true, "definitions": true, "$ref": true,
+	"title": true, "description": true, "default": true, "examples": true,
+	"type": true, "properties": true, "required": true, "items": true, "additionalProperties": true,
+}
+
+func fromSchema(schema map[string]interface{}, location string) (*Type, error) {
+	for keyword := range schema {
+		if !schemaKeywords[keyword] {
+			return nil, fmt.Errorf("%s: keyword '%s' can not be converted to a type", location, keyword)
+		}
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+			if strings.HasPrefix(ref, prefix) {
+				name, err := url.PathUnescape(strings.TrimPrefix(ref, prefix))
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid reference: %s", location, ref)
+				}
+				return Reference(pointerUnescaper.Replace(name)), nil
+			}
+		}
+		return nil, fmt.Errorf("%s: only references to definitions in the same schema are supported, got: %s", location, ref)
+	}
+
+	// Object types allow properties that they do not define, so only a schema that allows them too can be converted
+	if additional, ok := schema["additionalProperties"]; ok && !allowsAll(additional) {
+		return nil, fmt.Errorf("%s: only 'additionalProperties: true' can be converted to a type", location)
+	}
+
+	typeName, ok := schema["type"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: 'type' must be a single type name", location)
+	}
+
+	switch typeName {
+	case String, Boolean, Number:
+		return &Type{Base: typeName}, nil
+	case "integer":
+		return &Type{Base: Number}, nil
+	case Array:
+		t := &Type{Base: Array}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			listOf, err := fromSchema(items, location+"/items")
+			if err != nil {
+				return nil, err
+			}
+			t.ListOf = listOf
+		}
+		return t, nil
+	case Object:
+		t := &Type{Base: Object}
+		properties, _ := schema["properties"].(map[string]interface{})
+		if properties == nil {
+			return t, nil
+		}
+		required := map[string]bool{}
+		if names, ok := schema["required"].([]interface{}); ok {
+			for _, name := range names {
+				required[fmt.Sprintf("%v", name)] = true
+			}
+		}
+		t.Properties = ObjectProperties{}
+		for _, name := range sortedNames(properties) {
+			propSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s/properties/%s: must be an object", location, name)
+			}
+			prop := &Property{Name: name, Optional: !required[name], Default: propSchema["default"]}
+			prop.Description, _ = propSchema["description"].(string)
+			if hasTypeKeywords(propSchema) {
+				propType, err := fromSchema(propSchema, location+"/properties/"+name)
+				if err != nil {
+					return nil, err
+				}
+				prop.Type = propType
+			}
+			t.Properties = append(t.Properties, prop)
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("%s: type '%s' can not be converted", location, typeName)
+	}
+}
+
+// allowsAll tells if a schema accepts any value: true or an empty schema
+func allowsAll(schema interface{}) bool {
+	switch s := schema.(type) {
+	case bool:
+		return s
+	case map[string]interface{}:
+		return len(s) == 0
+	}
+	return false
+}
+
+func hasTypeKeywords(schema map[string]interface{}) bool {
+	_, hasType := schema["type"]
+	_, hasRef := schema["$ref"]
+	return hasType || hasRef
+}
+
+func isBaseType(name string) bool {
+	switch name {
+	case String, Number, Boolean, Object, Array:
+		return true
+	}
+	return false
+}
+
+func sortedNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"instacli/pkg/jsonschema"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestToJSONSchema(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Load([]byte(testTypes)); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := registry.ToJSONSchema(Reference("Recursive Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _ := json.Marshal(schema)
+	expected := `{"$defs":{"Recursive Type":{"properties":{"child":{"$ref":"#/$defs/Recursive%20Type"},"name":{"type":"string"}},"required":["name"],"type":"object"}},"$ref":"#/$defs/Recursive%20Type","$schema":"https://json-schema.org/draft/2020-12/schema"}`
+	if string(actual) != expected {
+		t.Errorf("Not equal:\n  Expected: %s\n  Actual:   %s", expected, actual)
+	}
+
+	// The schema accepts the same data as the type
+	compiled, err := jsonschema.NewLoader().Compile(schema, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := compiled.Validate(parse(t, "{name: a, child: {name: b}}")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := compiled.Validate(parse(t, "{name: a, child: {}}")); err == nil {
+		t.Error("expected missing name in child to be an error")
+	}
+}
+
+func TestFromJSONSchema(t *testing.T) {
+	schema := parse(t, `
+type: object
+required: [name]
+properties:
+  name: {type: string, description: The name}
+  age: {type: integer, default: 18}
+  friends: {type: array, items: {$ref: "#/$defs/Person"}}
+$defs:
+  Person:
+    type: object
+    properties:
+      name: {type: string}
+`).(map[string]interface{})
+
+	registry := NewRegistry()
+	typ, err := registry.FromJSONSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _ := yaml.Marshal(typ)
+	expected := `base: object
+properties:
+    age:
+        optional: true
+        default: 18
+        type:
+            base: number
+    friends:
+        optional: true
+        type:
+            base: array
+            list of: Person
+    name:
+        description: The name
+        type:
+            base: string
+`
+	if string(actual) != expected {
+		t.Errorf("Not equal:\n  Expected: %s\n  Actual:   %s", expected, actual)
+	}
+	if registry.Get("Person") == nil {
+		t.Error("expected definition Person to be registered")
+	}
+
+	if _, err := registry.FromJSONSchema(map[string]interface{}{"type": "string", "pattern": "x"}); err == nil {
+		t.Error("expected unsupported keyword to be an error")
+	}
+	if _, err := registry.FromJSONSchema(map[string]interface{}{"type": "object", "additionalProperties": false}); err == nil {
+		t.Error("expected closed object to be an error")
+	}
+	if _, err := registry.FromJSONSchema(map[string]interface{}{"type": "object", "additionalProperties": true}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Names with special characters are escaped in references
+	ref, err := registry.FromJSONSchema(map[string]interface{}{"$ref": "#/$defs/Either~1or%20~0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Name != "Either/or ~" {
+		t.Errorf("expected reference to 'Either/or ~', got %q", ref.Name)
+	}
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TypesFile is the file next to scripts that contains the named types of a directory
+const TypesFile = "types.yaml"
+
+// Registry holds named types
+type Registry struct {
+	types map[string]*Type
+}
+
+// NewRegistry creates a registry that contains the base types
+func NewRegistry() *Registry {
+	r := &Registry{types: make(map[string]*Type)}
+	for _, base := range []string{String, Number, Boolean, Object, Array} {
+		r.Register(base, &Type{Base: base})
+	}
+	return r
+}
+
+// Register adds a named type to the registry
+func (r *Registry) Register(name string, t *Type) {
+	r.types[name] = t
+}
+
+// Get returns the named type, or nil if it is not known
+func (r *Registry) Get(name string) *Type {
+	return r.types[name]
+}
+
+// Load registers all types defined in YAML, where each top-level key is the name of a type.
+func (r *Registry) Load(data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("error parsing types: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("types must be defined as an object with a type definition for each name")
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		name := root.Content[i].Value
+		t := &Type{}
+		if err := root.Content[i+1].Decode(t); err != nil {
+			return fmt.Errorf("error parsing type '%s': %w", name, err)
+		}
+		r.Register(name, t)
+	}
+	return nil
+}
+
+// LoadDir loads the types file in the given directory, if there is one.
+func (r *Registry) LoadDir(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, TypesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading types file: %w", err)
+	}
+	return r.Load(data)
+}
+
+// Resolve follows references to named types until it finds a type definition
+func (r *Registry) Resolve(t *Type) (*Type, error) {
+	seen := map[string]bool{}
+	for t.Name != "" {
+		if seen[t.Name] {
+			return nil, fmt.Errorf("type '%s' refers to itself", t.Name)
+		}
+		seen[t.Name] = true
+		found := r.Get(t.Name)
+		if found == nil {
+			return nil, fmt.Errorf("Type not found: %s", t.Name)
+		}
+		t = found
+	}
+	return t, nil
+}
+
+// baseOf returns the base type of a type definition, checking that the definition is consistent.
+func baseOf(t *Type) (string, error) {
+	switch {
+	case t.Properties != nil:
+		if t.Base != "" && t.Base != Object {
+			return "", fmt.Errorf("With properties defined on a type, base must be 'object', but was: '%s'", t.Base)
+		}
+		if t.ListOf != nil {
+			return "", fmt.Errorf("With properties defined on a type, 'list of' must not be defined")
+		}
+		return Object, nil
+	case t.ListOf != nil:
+		if t.Base != "" && t.Base != Array {
+			return "", fmt.Errorf("With list defined on a type, base must be 'array', but was: '%s'", t.Base)
+		}
+		return Array, nil
+	case t.Base != "":
+		return t.Base, nil
+	default:
+		return "", fmt.Errorf("Type definition must have a base")
+	}
+}
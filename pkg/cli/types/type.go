@@ -0,0 +1,138 @@
+package types
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Base types that are always available
+const (
+	String  = "string"
+	Number  = "number"
+	Boolean = "boolean"
+	Object  = "object"
+	Array   = "array"
+)
+
+// Type is a type definition. It is either a reference to a named type,
+// or a definition with a base type and optionally properties or a list element type.
+type Type struct {
+	Name       string           `yaml:"-"`
+	Base       string           `yaml:"base,omitempty"`
+	Properties ObjectProperties `yaml:"properties,omitempty"`
+	ListOf     *Type            `yaml:"list of,omitempty"`
+}
+
+// Property defines a property of an object type.
+type Property struct {
+	Name        string      `yaml:"-"`
+	Description string      `yaml:"description,omitempty"`
+	Optional    bool        `yaml:"optional,omitempty"`
+	Default     interface{} `yaml:"default,omitempty"`
+	Type        *Type       `yaml:"type,omitempty"`
+}
+
+// ObjectProperties holds the properties of an object type in the order they were declared.
+type ObjectProperties []*Property
+
+// Get returns the property with the given name, or nil if there is none.
+func (p ObjectProperties) Get(name string) *Property {
+	for _, prop := range p {
+		if prop.Name == name {
+			return prop
+		}
+	}
+	return nil
+}
+
+// Reference creates a type that refers to a named type.
+func Reference(name string) *Type {
+	return &Type{Name: name}
+}
+
+// UnmarshalYAML reads a type from either a type name or a type definition.
+func (t *Type) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = Type{Name: node.Value}
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: a type must be a type name or a type definition", node.Line)
+	}
+	type plain Type
+	var def plain
+	if err := node.Decode(&def); err != nil {
+		return err
+	}
+	*t = Type(def)
+	return nil
+}
+
+// MarshalYAML writes references as the type name.
+func (t *Type) MarshalYAML() (interface{}, error) {
+	if t.Name != "" {
+		return t.Name, nil
+	}
+	type plain Type
+	return (*plain)(t), nil
+}
+
+// UnmarshalYAML reads a property from either a type name or a property definition.
+func (p *Property) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*p = Property{Type: Reference(node.Value)}
+		return nil
+	}
+	type plain Property
+	var prop plain
+	if err := node.Decode(&prop); err != nil {
+		return err
+	}
+	*p = Property(prop)
+	return nil
+}
+
+// UnmarshalYAML reads the properties of an object type, keeping the order of declaration.
+func (p *ObjectProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: properties must be an object", node.Line)
+	}
+	props := make(ObjectProperties, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		prop := &Property{}
+		if err := node.Content[i+1].Decode(prop); err != nil {
+			return err
+		}
+		prop.Name = node.Content[i].Value
+		props = append(props, prop)
+	}
+	*p = props
+	return nil
+}
+
+// MarshalYAML writes the properties as an object in the order of declaration.
+func (p ObjectProperties) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, prop := range p {
+		value := &yaml.Node{}
+		if err := value.Encode(prop); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: prop.Name}, value)
+	}
+	return node, nil
+}
+
+// FromData converts generic data, like the arguments of a command, into a type.
+func FromData(data interface{}) (*Type, error) {
+	source, err := yaml.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	t := &Type{}
+	if err := yaml.Unmarshal(source, t); err != nil {
+		return nil, fmt.Errorf("invalid type definition: %w", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Violation describes a place in the data that does not match its type
+type Violation struct {
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// ValidationError is returned when data does not match a type. It contains all violations.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return "Type validation errors:\n  " + strings.Join(messages, "\n  ")
+}
+
+// Check validates data against a type and returns a *ValidationError if the data does not match.
+func (r *Registry) Check(data interface{}, t *Type) error {
+	violations, err := r.Validate(data, t)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// Validate checks data against a type and returns all violations.
+// An error is returned if the type definition itself is invalid.
+func (r *Registry) Validate(data interface{}, t *Type) ([]Violation, error) {
+	var violations []Violation
+	if err := r.validate(data, t, "$", &violations); err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+func (r *Registry) validate(data interface{}, t *Type, path string, violations *[]Violation) error {
+	def, err := r.Resolve(t)
+	if err != nil {
+		return err
+	}
+	base, err := baseOf(def)
+	if err != nil {
+		return err
+	}
+
+	actual := KindOf(data)
+	if base != actual && !(base == Number && actual == "integer") {
+		*violations = append(*violations, Violation{
+			Path:    path,
+			Message: fmt.Sprintf("Data should be %s but is %s", base, actual),
+		})
+		return nil
+	}
+
+	switch base {
+	case Object:
+		fields := data.(map[string]interface{})
+		for _, prop := range def.Properties {
+			value, ok := fields[prop.Name]
+			if !ok {
+				if !prop.Optional {
+					*violations = append(*violations, Violation{
+						Path:    path,
+						Message: fmt.Sprintf("Missing property: %s", prop.Name),
+					})
+				}
+				continue
+			}
+			if prop.Type == nil {
+				continue
+			}
+			if err := r.validate(value, prop.Type, childPath(path, prop.Name), violations); err != nil {
+				return err
+			}
+		}
+	case Array:
+		if def.ListOf == nil {
+			return nil
+		}
+		for i, item := range data.([]interface{}) {
+			if err := r.validate(item, def.ListOf, fmt.Sprintf("%s[%d]", path, i), violations); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var simpleKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func childPath(path, key string) string {
+	if simpleKey.MatchString(key) {
+		return path + "." + key
+	}
+	return fmt.Sprintf("%s[%q]", path, key)
+}
+
+// KindOf returns the name of the base type of a value as produced by the YAML decoder:
+// null, string, integer, number, boolean, object or array.
+func KindOf(data interface{}) string {
+	switch data.(type) {
+	case nil:
+		return "null"
+	case string:
+		return String
+	case int, int64, uint64:
+		return "integer"
+	case float64, float32:
+		return Number
+	case bool:
+		return Boolean
+	case map[string]interface{}:
+		return Object
+	case []interface{}:
+		return Array
+	default:
+		return fmt.Sprintf("%T", data)
+	}
+}
@@ -0,0 +1,134 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testTypes = `
+Person:
+  base: object
+  properties:
+    name: string
+
+Recursive Type:
+  base: object
+  properties:
+    name: string
+    child:
+      type: Recursive Type
+      optional: true
+`
+
+func parse(t *testing.T, source string) interface{} {
+	t.Helper()
+	var data interface{}
+	if err := yaml.Unmarshal([]byte(source), &data); err != nil {
+		t.Fatalf("invalid test data: %v", err)
+	}
+	return data
+}
+
+func validate(t *testing.T, item string, typeDefinition string) []Violation {
+	t.Helper()
+	registry := NewRegistry()
+	if err := registry.Load([]byte(testTypes)); err != nil {
+		t.Fatal(err)
+	}
+	typ, err := FromData(parse(t, typeDefinition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations, err := registry.Validate(parse(t, item), typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return violations
+}
+
+func TestValidTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		item string
+		typ  string
+	}{
+		{"String type", "Hes", "string"},
+		{"Number type", "1.5", "number"},
+		{"Integer is a number", "1", "number"},
+		{"Object type", "name: Hes", "object"},
+		{"Array type", "[{name: Hes}]", "array"},
+		{"Object properties", "name: Hes", "{base: object, properties: {name: string}}"},
+		{"Implicit object definition", "name: Hes", "{properties: {name: string}}"},
+		{"Define a list", "[{name: Alice}, {name: Bob}]", "{base: array, list of: Person}"},
+		{"Optional property", "name-x: Alice", "{properties: {name: {type: string, optional: true}}}"},
+		{"Recursive object", "{name: Alice, child: {name: Bob, child: {name: Charlie}}}", "Recursive Type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if violations := validate(t, tt.item, tt.typ); len(violations) > 0 {
+				t.Errorf("unexpected violations: %v", violations)
+			}
+		})
+	}
+}
+
+func TestInvalidTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     string
+		typ      string
+		expected []string
+	}{
+		{"Not a string", "[Hes]", "string", []string{"$: Data should be string but is array"}},
+		{"Not an object", "Hes", "object", []string{"$: Data should be object but is string"}},
+		{"Not an array", "name: Hes", "array", []string{"$: Data should be array but is object"}},
+		{"Invalid object property", "name: Hes", "{properties: {name: object}}", []string{"$.name: Data should be object but is string"}},
+		{"Faulty object", "name-x: Alice", "Person", []string{"$: Missing property: name"}},
+		{"Faulty list", "[{name-x: Alice}, {name: 1}]", "{list of: Person}", []string{
+			"$[0]: Missing property: name",
+			"$[1].name: Data should be string but is integer",
+		}},
+		{"Recursive object", "{name: Alice, child: {child: {name: [Charlie]}}}", "Recursive Type", []string{
+			"$.child: Missing property: name",
+			"$.child.child.name: Data should be string but is array",
+		}},
+		{"Key with spaces", "{first name: 1}", "{properties: {first name: string}}", []string{
+			`$["first name"]: Data should be string but is integer`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := validate(t, tt.item, tt.typ)
+			var actual []string
+			for _, v := range violations {
+				actual = append(actual, v.String())
+			}
+			if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Not equal:\n  Expected: %v\n  Actual:   %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestInvalidTypeDefinitions(t *testing.T) {
+	registry := NewRegistry()
+	tests := map[string]string{
+		"Unknown":                          "Type not found: Unknown",
+		"{base: string, properties: {}}":   "With properties defined on a type, base must be 'object', but was: 'string'",
+		"{base: object, list of: string}":  "With list defined on a type, base must be 'array', but was: 'object'",
+		"{properties: {a: Missing}}":       "Type not found: Missing",
+		"{description: no base or fields}": "Type definition must have a base",
+	}
+	for definition, expected := range tests {
+		typ, err := FromData(parse(t, definition))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = registry.Validate(parse(t, "{a: b}"), typ)
+		if err == nil || err.Error() != expected {
+			t.Errorf("%s\n  Expected error: %s\n  Actual:         %v", definition, expected, err)
+		}
+	}
+}
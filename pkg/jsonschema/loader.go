@@ -0,0 +1,252 @@
+// Package jsonschema validates data decoded from YAML or JSON against JSON Schema (draft 2020-12).
+package jsonschema
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Loader loads schemas and keeps track of all schema resources it has seen,
+// so references between schemas and across files can be resolved.
+type Loader struct {
+	mu        sync.Mutex
+	mappings  []mapping
+	resources map[string]*resource
+}
+
+// mapping makes schemas with URIs starting with a prefix available from a file system.
+type mapping struct {
+	prefix string
+	fsys   fs.FS
+	dir    string
+}
+
+// resource is a schema together with the base URI that references inside it are resolved against.
+type resource struct {
+	node interface{}
+	base *url.URL
+}
+
+// Schema is a loaded schema that can validate data
+type Schema struct {
+	loader *Loader
+	root   *resource
+}
+
+// NewLoader creates a loader that reads schemas with 'file' URIs from disk.
+func NewLoader() *Loader {
+	return &Loader{resources: make(map[string]*resource)}
+}
+
+// Map makes schemas with URIs starting with prefix available from a directory in a file system.
+// For example, with prefix "https://example.com/schemas" and directory "schemas",
+// the URI "https://example.com/schemas/person.yaml" is read from "schemas/person.yaml".
+func (l *Loader) Map(prefix string, fsys fs.FS, dir string) {
+	l.mappings = append(l.mappings, mapping{prefix: strings.TrimSuffix(prefix, "/"), fsys: fsys, dir: dir})
+}
+
+// Load loads the schema with the given URI. A URI without scheme is taken as a path on disk.
+func (l *Loader) Load(uri string) (*Schema, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema URI '%s': %w", uri, err)
+	}
+	if u.Scheme == "" {
+		u = FileURI(uri)
+	}
+	root, err := l.resolve(u)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{loader: l, root: root}, nil
+}
+
+// Compile prepares an inline schema. References inside it are resolved against baseURI,
+// which may be a directory on disk.
+func (l *Loader) Compile(schema interface{}, baseURI string) (*Schema, error) {
+	base, err := url.Parse(baseURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URI '%s': %w", baseURI, err)
+	}
+	if base.Scheme == "" {
+		base = FileURI(filepath.Join(baseURI, "inline-schema"))
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	root := l.index(schema, base)
+	return &Schema{loader: l, root: root}, nil
+}
+
+// FileURI converts a file path to a 'file' URI.
+func FileURI(file string) *url.URL {
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	return &url.URL{Scheme: "file", Path: filepath.ToSlash(file)}
+}
+
+// resolve finds the schema resource for a URI, loading the document it is in if needed.
+func (l *Loader) resolve(u *url.URL) (*resource, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	doc := withoutFragment(u)
+	res, ok := l.resources[doc.String()]
+	if !ok {
+		node, err := l.fetch(doc)
+		if err != nil {
+			return nil, err
+		}
+		res = l.index(node, doc)
+	}
+
+	fragment := u.Fragment
+	switch {
+	case fragment == "":
+		return res, nil
+	case strings.HasPrefix(fragment, "/"):
+		return res.pointer(fragment)
+	default:
+		anchor, ok := l.resources[doc.String()+"#"+fragment]
+		if !ok {
+			return nil, fmt.Errorf("anchor not found: %s", u)
+		}
+		return anchor, nil
+	}
+}
+
+// dynamicAnchor returns the schema in the resource with the given base URI that has a $dynamicAnchor with the name,
+// or nil if there is none.
+func (l *Loader) dynamicAnchor(base *url.URL, name string) *resource {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	res, ok := l.resources[base.String()+"#"+name]
+	if !ok {
+		return nil
+	}
+	if node, ok := res.node.(map[string]interface{}); !ok || node["$dynamicAnchor"] != name {
+		return nil
+	}
+	return res
+}
+
+// fetch reads and parses the document for a URI.
+func (l *Loader) fetch(u *url.URL) (interface{}, error) {
+	var data []byte
+	var err error
+
+	uri := u.String()
+	found := false
+	for _, m := range l.mappings {
+		if strings.HasPrefix(uri, m.prefix+"/") {
+			rel, perr := url.PathUnescape(strings.TrimPrefix(uri, m.prefix+"/"))
+			if perr != nil {
+				return nil, perr
+			}
+			data, err = fs.ReadFile(m.fsys, path.Join(m.dir, rel))
+			found = true
+			break
+		}
+	}
+	if !found {
+		if u.Scheme != "file" {
+			return nil, fmt.Errorf("can not load schema %s: only local schemas are supported", uri)
+		}
+		data, err = os.ReadFile(filepath.FromSlash(u.Path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema %s: %w", uri, err)
+	}
+
+	var node interface{}
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("error parsing schema %s: %w", uri, err)
+	}
+	return node, nil
+}
+
+// index registers a schema document and all resources with an $id or $anchor inside it.
+func (l *Loader) index(node interface{}, base *url.URL) *resource {
+	root := &resource{node: node, base: base}
+	l.resources[base.String()] = root
+	l.indexNode(node, base)
+	return root
+}
+
+func (l *Loader) indexNode(node interface{}, base *url.URL) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if id, ok := n["$id"].(string); ok {
+			if u, err := base.Parse(id); err == nil {
+				base = withoutFragment(u)
+				l.resources[base.String()] = &resource{node: n, base: base}
+			}
+		}
+		for _, keyword := range []string{"$anchor", "$dynamicAnchor"} {
+			if anchor, ok := n[keyword].(string); ok {
+				l.resources[base.String()+"#"+anchor] = &resource{node: n, base: base}
+			}
+		}
+		for key, child := range n {
+			switch key {
+			case "enum", "const", "default", "examples":
+				// Data, not schemas
+				continue
+			}
+			l.indexNode(child, base)
+		}
+	case []interface{}:
+		for _, child := range n {
+			l.indexNode(child, base)
+		}
+	}
+}
+
+// pointer follows a JSON pointer inside a resource, keeping track of base URI changes along the way.
+func (r *resource) pointer(pointer string) (*resource, error) {
+	node := r.node
+	base := r.base
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("can not resolve '%s' in %s", pointer, r.base)
+			}
+			node = child
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(n) {
+				return nil, fmt.Errorf("can not resolve '%s' in %s", pointer, r.base)
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("can not resolve '%s' in %s", pointer, r.base)
+		}
+		if m, ok := node.(map[string]interface{}); ok {
+			if id, ok := m["$id"].(string); ok {
+				if u, err := base.Parse(id); err == nil {
+					base = withoutFragment(u)
+				}
+			}
+		}
+	}
+	return &resource{node: node, base: base}, nil
+}
+
+func withoutFragment(u *url.URL) *url.URL {
+	c := *u
+	c.Fragment = ""
+	c.RawFragment = ""
+	return &c
+}
@@ -0,0 +1,636 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"instacli/pkg/values"
+)
+
+// maxDepth guards against references that loop without consuming data
+const maxDepth = 500
+
+// Error is a single place where data does not match the schema
+type Error struct {
+	// InstanceLocation is a JSON pointer to the invalid data
+	InstanceLocation string
+	// KeywordLocation is a JSON pointer to the keyword in the schema that failed
+	KeywordLocation string
+	Message         string
+}
+
+func (e Error) String() string {
+	location := e.InstanceLocation
+	if location == "" {
+		location = "/"
+	}
+	return location + ": " + e.Message
+}
+
+// ValidationError is returned when data does not match a schema. It contains all errors.
+type ValidationError struct {
+	Errors []Error
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.String()
+	}
+	return "Schema validation errors:\n  " + strings.Join(messages, "\n  ")
+}
+
+// Validate checks data against the schema. It returns a *ValidationError with all errors if the data is invalid,
+// or another error if the schema itself can not be used.
+func (s *Schema) Validate(data interface{}) error {
+	v := &validator{loader: s.loader}
+	r := v.validate(s.root.node, s.root.base, data, "", "", 0)
+	if v.err != nil {
+		return v.err
+	}
+	if len(r.errors) > 0 {
+		return &ValidationError{Errors: r.errors}
+	}
+	return nil
+}
+
+type validator struct {
+	loader *Loader
+	// err holds the first problem with the schema itself
+	err error
+	// scope has the base URIs of the schema resources that are being evaluated, the outermost first. A $dynamicRef
+	// looks for its anchor in them.
+	scope []*url.URL
+}
+
+// result holds the errors and the annotations needed for unevaluatedProperties and unevaluatedItems
+type result struct {
+	errors   []Error
+	props    map[string]bool
+	items    map[int]bool
+	allItems bool
+}
+
+func (r *result) valid() bool {
+	return len(r.errors) == 0
+}
+
+func (r *result) fail(instLoc, kwLoc, format string, args ...interface{}) {
+	r.errors = append(r.errors, Error{InstanceLocation: instLoc, KeywordLocation: kwLoc, Message: fmt.Sprintf(format, args...)})
+}
+
+// merge takes over the errors and annotations of a subschema
+func (r *result) merge(sub *result) {
+	r.errors = append(r.errors, sub.errors...)
+	r.mergeAnnotations(sub)
+}
+
+func (r *result) mergeAnnotations(sub *result) {
+	for p := range sub.props {
+		r.markProperty(p)
+	}
+	for i := range sub.items {
+		r.markItem(i)
+	}
+	r.allItems = r.allItems || sub.allItems
+}
+
+func (r *result) markProperty(name string) {
+	if r.props == nil {
+		r.props = map[string]bool{}
+	}
+	r.props[name] = true
+}
+
+func (r *result) markItem(index int) {
+	if r.items == nil {
+		r.items = map[int]bool{}
+	}
+	r.items[index] = true
+}
+
+func (v *validator) schemaError(kwLoc string, format string, args ...interface{}) {
+	if v.err == nil {
+		v.err = fmt.Errorf("invalid schema at #%s: %s", kwLoc, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) validate(schema interface{}, base *url.URL, data interface{}, instLoc, kwLoc string, depth int) *result {
+	r := &result{}
+	if depth > maxDepth {
+		v.schemaError(kwLoc, "references are nested too deep")
+		return r
+	}
+
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			r.fail(instLoc, kwLoc, "no value is allowed here")
+		}
+		return r
+	case map[string]interface{}:
+		v.validateObject(s, base, data, instLoc, kwLoc, depth, r)
+	default:
+		// Not a valid schema. Like other validators, accept any value here rather than failing on a mistake
+		// in the schema, for example 'type: string' written as a property schema.
+	}
+	return r
+}
+
+func (v *validator) validateObject(s map[string]interface{}, base *url.URL, data interface{}, instLoc, kwLoc string, depth int, r *result) {
+	if id, ok := s["$id"].(string); ok {
+		if u, err := base.Parse(id); err == nil {
+			base = withoutFragment(u)
+		}
+	}
+	if len(v.scope) == 0 || v.scope[len(v.scope)-1].String() != base.String() {
+		v.scope = append(v.scope, base)
+		defer func() { v.scope = v.scope[:len(v.scope)-1] }()
+	}
+
+	// References
+	for _, keyword := range []string{"$ref", "$dynamicRef"} {
+		ref, ok := s[keyword].(string)
+		if !ok {
+			continue
+		}
+		target, err := base.Parse(ref)
+		if err != nil {
+			v.schemaError(kwLoc+"/"+keyword, "invalid reference '%s'", ref)
+			continue
+		}
+		res, err := v.loader.resolve(target)
+		if err != nil {
+			v.schemaError(kwLoc+"/"+keyword, "%v", err)
+			continue
+		}
+		if keyword == "$dynamicRef" {
+			res = v.dynamicTarget(target.Fragment, res)
+		}
+		r.merge(v.validate(res.node, res.base, data, instLoc, kwLoc+"/"+keyword, depth+1))
+	}
+
+	v.validateType(s, data, instLoc, kwLoc, r)
+	v.validateEnum(s, data, instLoc, kwLoc, r)
+
+	switch d := data.(type) {
+	case string:
+		v.validateString(s, d, instLoc, kwLoc, r)
+	case map[string]interface{}:
+		v.validateProperties(s, base, d, instLoc, kwLoc, depth, r)
+	case []interface{}:
+		v.validateItems(s, base, d, instLoc, kwLoc, depth, r)
+	default:
+		if n, ok := toFloat(data); ok {
+			v.validateNumber(s, n, instLoc, kwLoc, r)
+		}
+	}
+
+	v.validateCombinations(s, base, data, instLoc, kwLoc, depth, r)
+
+	// Unevaluated properties and items look at everything that was evaluated so far, so they go last
+	if unevaluated, ok := s["unevaluatedProperties"]; ok {
+		if obj, ok := data.(map[string]interface{}); ok {
+			for _, key := range sortedKeys(obj) {
+				if r.props[key] {
+					continue
+				}
+				if b, ok := unevaluated.(bool); ok && !b {
+					r.fail(instLoc, kwLoc+"/unevaluatedProperties", "property '%s' is not allowed", key)
+					continue
+				}
+				sub := v.validate(unevaluated, base, obj[key], instLoc+"/"+escape(key), kwLoc+"/unevaluatedProperties", depth+1)
+				r.errors = append(r.errors, sub.errors...)
+				r.markProperty(key)
+			}
+		}
+	}
+	if unevaluated, ok := s["unevaluatedItems"]; ok && !r.allItems {
+		if arr, ok := data.([]interface{}); ok {
+			for i, item := range arr {
+				if r.items[i] {
+					continue
+				}
+				sub := v.validate(unevaluated, base, item, fmt.Sprintf("%s/%d", instLoc, i), kwLoc+"/unevaluatedItems", depth+1)
+				r.errors = append(r.errors, sub.errors...)
+			}
+			r.allItems = true
+		}
+	}
+}
+
+// dynamicTarget returns the schema that a $dynamicRef refers to. If the reference is to a $dynamicAnchor, it is the
+// outermost schema resource in the dynamic scope with that anchor. Otherwise it is the schema the reference resolves
+// to, like with $ref.
+func (v *validator) dynamicTarget(anchor string, res *resource) *resource {
+	if node, ok := res.node.(map[string]interface{}); !ok || anchor == "" || node["$dynamicAnchor"] != anchor {
+		return res
+	}
+	for _, base := range v.scope {
+		if found := v.loader.dynamicAnchor(base, anchor); found != nil {
+			return found
+		}
+	}
+	return res
+}
+
+func (v *validator) validateType(s map[string]interface{}, data interface{}, instLoc, kwLoc string, r *result) {
+	typ, ok := s["type"]
+	if !ok {
+		return
+	}
+	var allowed []string
+	switch t := typ.(type) {
+	case string:
+		allowed = []string{t}
+	case []interface{}:
+		for _, elem := range t {
+			if name, ok := elem.(string); ok {
+				allowed = append(allowed, name)
+			}
+		}
+	default:
+		v.schemaError(kwLoc+"/type", "type must be a string or an array")
+		return
+	}
+	for _, name := range allowed {
+		if hasType(data, name) {
+			return
+		}
+	}
+	r.fail(instLoc, kwLoc+"/type", "expected %s, but got %s", strings.Join(allowed, " or "), typeOf(data))
+}
+
+func (v *validator) validateEnum(s map[string]interface{}, data interface{}, instLoc, kwLoc string, r *result) {
+	if constant, ok := s["const"]; ok && !values.Equal(data, constant) {
+		r.fail(instLoc, kwLoc+"/const", "value must be %s", display(constant))
+	}
+	if enum, ok := s["enum"]; ok {
+		allowed, ok := enum.([]interface{})
+		if !ok {
+			v.schemaError(kwLoc+"/enum", "enum must be an array")
+			return
+		}
+		for _, value := range allowed {
+			if values.Equal(data, value) {
+				return
+			}
+		}
+		r.fail(instLoc, kwLoc+"/enum", "value must be one of %s", display(allowed))
+	}
+}
+
+func (v *validator) validateNumber(s map[string]interface{}, n float64, instLoc, kwLoc string, r *result) {
+	if m, ok := number(s, "multipleOf"); ok && m > 0 {
+		q := n / m
+		if math.IsInf(q, 0) || math.Abs(q-math.Round(q)) > 1e-9 {
+			r.fail(instLoc, kwLoc+"/multipleOf", "%v is not a multiple of %v", n, m)
+		}
+	}
+	if m, ok := number(s, "maximum"); ok && n > m {
+		r.fail(instLoc, kwLoc+"/maximum", "%v is greater than the maximum of %v", n, m)
+	}
+	if m, ok := number(s, "exclusiveMaximum"); ok && n >= m {
+		r.fail(instLoc, kwLoc+"/exclusiveMaximum", "%v must be less than %v", n, m)
+	}
+	if m, ok := number(s, "minimum"); ok && n < m {
+		r.fail(instLoc, kwLoc+"/minimum", "%v is less than the minimum of %v", n, m)
+	}
+	if m, ok := number(s, "exclusiveMinimum"); ok && n <= m {
+		r.fail(instLoc, kwLoc+"/exclusiveMinimum", "%v must be greater than %v", n, m)
+	}
+}
+
+func (v *validator) validateString(s map[string]interface{}, text string, instLoc, kwLoc string, r *result) {
+	length := utf8.RuneCountInString(text)
+	if m, ok := number(s, "maxLength"); ok && float64(length) > m {
+		r.fail(instLoc, kwLoc+"/maxLength", "length must be at most %v, but is %d", m, length)
+	}
+	if m, ok := number(s, "minLength"); ok && float64(length) < m {
+		r.fail(instLoc, kwLoc+"/minLength", "length must be at least %v, but is %d", m, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			v.schemaError(kwLoc+"/pattern", "%v", err)
+		} else if !re.MatchString(text) {
+			r.fail(instLoc, kwLoc+"/pattern", "'%s' does not match pattern '%s'", text, pattern)
+		}
+	}
+	if format, ok := s["format"].(string); ok {
+		if check, known := formats[format]; known && !check(text) {
+			r.fail(instLoc, kwLoc+"/format", "'%s' is not a valid %s", text, format)
+		}
+	}
+}
+
+func (v *validator) validateProperties(s map[string]interface{}, base *url.URL, obj map[string]interface{}, instLoc, kwLoc string, depth int, r *result) {
+	keys := sortedKeys(obj)
+
+	if m, ok := number(s, "maxProperties"); ok && float64(len(obj)) > m {
+		r.fail(instLoc, kwLoc+"/maxProperties", "must have at most %v properties, but has %d", m, len(obj))
+	}
+	if m, ok := number(s, "minProperties"); ok && float64(len(obj)) < m {
+		r.fail(instLoc, kwLoc+"/minProperties", "must have at least %v properties, but has %d", m, len(obj))
+	}
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := obj[key]; !present {
+					r.fail(instLoc, kwLoc+"/required", "missing property '%s'", key)
+				}
+			}
+		}
+	}
+	if dependent, ok := s["dependentRequired"].(map[string]interface{}); ok {
+		for _, key := range sortedKeys(dependent) {
+			if _, present := obj[key]; !present {
+				continue
+			}
+			names, _ := dependent[key].([]interface{})
+			for _, name := range names {
+				if other, ok := name.(string); ok {
+					if _, present := obj[other]; !present {
+						r.fail(instLoc, kwLoc+"/dependentRequired", "property '%s' is required when '%s' is present", other, key)
+					}
+				}
+			}
+		}
+	}
+	if dependent, ok := s["dependentSchemas"].(map[string]interface{}); ok {
+		for _, key := range sortedKeys(dependent) {
+			if _, present := obj[key]; present {
+				r.merge(v.validate(dependent[key], base, obj, instLoc, kwLoc+"/dependentSchemas/"+escape(key), depth+1))
+			}
+		}
+	}
+	if names, ok := s["propertyNames"]; ok {
+		for _, key := range keys {
+			sub := v.validate(names, base, key, instLoc+"/"+escape(key), kwLoc+"/propertyNames", depth+1)
+			r.errors = append(r.errors, sub.errors...)
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	for _, key := range sortedKeys(properties) {
+		value, present := obj[key]
+		if !present {
+			continue
+		}
+		r.merge(v.validate(properties[key], base, value, instLoc+"/"+escape(key), kwLoc+"/properties/"+escape(key), depth+1))
+		r.markProperty(key)
+	}
+
+	patterns, _ := s["patternProperties"].(map[string]interface{})
+	matchesPattern := map[string]bool{}
+	for _, pattern := range sortedKeys(patterns) {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			v.schemaError(kwLoc+"/patternProperties", "%v", err)
+			continue
+		}
+		for _, key := range keys {
+			if !re.MatchString(key) {
+				continue
+			}
+			matchesPattern[key] = true
+			r.merge(v.validate(patterns[pattern], base, obj[key], instLoc+"/"+escape(key), kwLoc+"/patternProperties/"+escape(pattern), depth+1))
+			r.markProperty(key)
+		}
+	}
+
+	if additional, ok := s["additionalProperties"]; ok {
+		for _, key := range keys {
+			if _, declared := properties[key]; declared || matchesPattern[key] {
+				continue
+			}
+			if b, ok := additional.(bool); ok && !b {
+				r.fail(instLoc, kwLoc+"/additionalProperties", "property '%s' is not allowed", key)
+				continue
+			}
+			r.merge(v.validate(additional, base, obj[key], instLoc+"/"+escape(key), kwLoc+"/additionalProperties", depth+1))
+			r.markProperty(key)
+		}
+	}
+}
+
+func (v *validator) validateItems(s map[string]interface{}, base *url.URL, arr []interface{}, instLoc, kwLoc string, depth int, r *result) {
+	if m, ok := number(s, "maxItems"); ok && float64(len(arr)) > m {
+		r.fail(instLoc, kwLoc+"/maxItems", "must have at most %v items, but has %d", m, len(arr))
+	}
+	if m, ok := number(s, "minItems"); ok && float64(len(arr)) < m {
+		r.fail(instLoc, kwLoc+"/minItems", "must have at least %v items, but has %d", m, len(arr))
+	}
+	if unique, ok := s["uniqueItems"].(bool); ok && unique {
+	outer:
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if values.Equal(arr[i], arr[j]) {
+					r.fail(instLoc, kwLoc+"/uniqueItems", "items %d and %d are equal", i, j)
+					break outer
+				}
+			}
+		}
+	}
+
+	prefix, _ := s["prefixItems"].([]interface{})
+	for i, schema := range prefix {
+		if i >= len(arr) {
+			break
+		}
+		r.merge(v.validate(schema, base, arr[i], fmt.Sprintf("%s/%d", instLoc, i), fmt.Sprintf("%s/prefixItems/%d", kwLoc, i), depth+1))
+		r.markItem(i)
+	}
+	if items, ok := s["items"]; ok {
+		for i := len(prefix); i < len(arr); i++ {
+			r.merge(v.validate(items, base, arr[i], fmt.Sprintf("%s/%d", instLoc, i), kwLoc+"/items", depth+1))
+		}
+		r.allItems = true
+	}
+
+	if contains, ok := s["contains"]; ok {
+		matches := 0
+		for i, item := range arr {
+			if v.validate(contains, base, item, fmt.Sprintf("%s/%d", instLoc, i), kwLoc+"/contains", depth+1).valid() {
+				matches++
+				r.markItem(i)
+			}
+		}
+		minContains := 1.0
+		if m, ok := number(s, "minContains"); ok {
+			minContains = m
+		}
+		if float64(matches) < minContains {
+			r.fail(instLoc, kwLoc+"/contains", "must contain at least %v matching items, but contains %d", minContains, matches)
+		}
+		if m, ok := number(s, "maxContains"); ok && float64(matches) > m {
+			r.fail(instLoc, kwLoc+"/maxContains", "must contain at most %v matching items, but contains %d", m, matches)
+		}
+	}
+}
+
+func (v *validator) validateCombinations(s map[string]interface{}, base *url.URL, data interface{}, instLoc, kwLoc string, depth int, r *result) {
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for i, schema := range all {
+			r.merge(v.validate(schema, base, data, instLoc, fmt.Sprintf("%s/allOf/%d", kwLoc, i), depth+1))
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for i, schema := range anyOf {
+			sub := v.validate(schema, base, data, instLoc, fmt.Sprintf("%s/anyOf/%d", kwLoc, i), depth+1)
+			if sub.valid() {
+				matched = true
+				r.mergeAnnotations(sub)
+			}
+		}
+		if !matched {
+			r.fail(instLoc, kwLoc+"/anyOf", "value must match at least one of the schemas in anyOf")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		var matches []int
+		for i, schema := range oneOf {
+			sub := v.validate(schema, base, data, instLoc, fmt.Sprintf("%s/oneOf/%d", kwLoc, i), depth+1)
+			if sub.valid() {
+				matches = append(matches, i)
+				r.mergeAnnotations(sub)
+			}
+		}
+		switch len(matches) {
+		case 1:
+		case 0:
+			r.fail(instLoc, kwLoc+"/oneOf", "value must match exactly one of the schemas in oneOf, but matches none")
+		default:
+			r.fail(instLoc, kwLoc+"/oneOf", "value must match exactly one of the schemas in oneOf, but matches %v", matches)
+		}
+	}
+	if not, ok := s["not"]; ok {
+		if v.validate(not, base, data, instLoc, kwLoc+"/not", depth+1).valid() {
+			r.fail(instLoc, kwLoc+"/not", "value must not match the schema in 'not'")
+		}
+	}
+	if condition, ok := s["if"]; ok {
+		sub := v.validate(condition, base, data, instLoc, kwLoc+"/if", depth+1)
+		if sub.valid() {
+			r.mergeAnnotations(sub)
+			if then, ok := s["then"]; ok {
+				r.merge(v.validate(then, base, data, instLoc, kwLoc+"/then", depth+1))
+			}
+		} else if otherwise, ok := s["else"]; ok {
+			r.merge(v.validate(otherwise, base, data, instLoc, kwLoc+"/else", depth+1))
+		}
+	}
+}
+
+// hasType checks data against one of the JSON Schema type names
+func hasType(data interface{}, name string) bool {
+	switch name {
+	case "null":
+		return data == nil
+	case "boolean":
+		_, ok := data.(bool)
+		return ok
+	case "string":
+		_, ok := data.(string)
+		return ok
+	case "object":
+		_, ok := data.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := data.([]interface{})
+		return ok
+	case "number":
+		_, ok := toFloat(data)
+		return ok
+	case "integer":
+		n, ok := toFloat(data)
+		return ok && n == math.Trunc(n) && !math.IsInf(n, 0)
+	}
+	// Unknown type names, like 'any' in some of the spec schemas, do not restrict the value
+	return true
+}
+
+func typeOf(data interface{}) string {
+	for _, name := range []string{"null", "boolean", "string", "object", "array", "integer", "number"} {
+		if hasType(data, name) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", data)
+}
+
+func toFloat(data interface{}) (float64, bool) {
+	switch n := data.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func number(s map[string]interface{}, keyword string) (float64, bool) {
+	value, ok := s[keyword]
+	if !ok {
+		return 0, false
+	}
+	return toFloat(value)
+}
+
+func display(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = display(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+var patterns sync.Map
+
+// compilePattern compiles a regular expression. Go uses RE2 syntax, which covers most of ECMA 262
+// but not lookarounds and backreferences.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported pattern '%s': %w", pattern, err)
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// escape encodes a property name for use in a JSON pointer
+func escape(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
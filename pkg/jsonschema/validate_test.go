@@ -0,0 +1,244 @@
+package jsonschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gopkg.in/yaml.v3"
+)
+
+func parse(t *testing.T, source string) interface{} {
+	t.Helper()
+	var data interface{}
+	if err := yaml.Unmarshal([]byte(source), &data); err != nil {
+		t.Fatalf("invalid test data: %v", err)
+	}
+	return data
+}
+
+// errorsOf validates data and returns the validation errors as text
+func errorsOf(t *testing.T, schema *Schema, data string) []string {
+	t.Helper()
+	err := schema.Validate(parse(t, data))
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var messages []string
+	for _, e := range validationErr.Errors {
+		messages = append(messages, e.String())
+	}
+	return messages
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		data     string
+		expected []string
+	}{
+		{"String with pattern", "{type: string, pattern: Hello}", "Hello world", nil},
+		{"Wrong type", "{type: object}", "Hello world", []string{"/: expected object, but got string"}},
+		{"Multiple types", "{type: [string, object]}", "[1]", []string{"/: expected string or object, but got array"}},
+		{"Integer is a number", "{type: number, minimum: 1}", "1", nil},
+		{"Float with fraction is not an integer", "{type: integer}", "1.5", []string{"/: expected integer, but got number"}},
+		{"Whole float is an integer", "{type: integer}", "2.0", nil},
+		{"Unknown type allows anything", "{type: any}", "{a: 1}", nil},
+		{"All errors are reported", `
+type: object
+required: [name, age]
+properties:
+  name: {type: string, minLength: 2}
+  tags: {type: array, items: {type: string}}
+additionalProperties: false
+`, "{name: A, tags: [a, 1], extra: true}", []string{
+			"/: missing property 'age'",
+			"/name: length must be at least 2, but is 1",
+			"/tags/1: expected string, but got integer",
+			"/: property 'extra' is not allowed",
+		}},
+		{"Definitions", `
+$ref: "#/$defs/person"
+$defs:
+  person:
+    type: object
+    properties:
+      child: {$ref: "#/$defs/person"}
+      name: {type: string}
+`, "{name: Alice, child: {name: Bob, child: {name: 3}}}", []string{"/child/child/name: expected string, but got integer"}},
+		{"Anchor", `
+properties:
+  name: {$ref: "#name"}
+definitions:
+  name: {$anchor: name, type: string}
+`, "{name: 1}", []string{"/name: expected string, but got integer"}},
+		{"Enum and const", `
+properties:
+  color: {enum: [red, green]}
+  count: {const: 1}
+`, "{color: blue, count: 1.0}", []string{`/color: value must be one of ["red", "green"]`}},
+		{"Numbers", "{multipleOf: 0.1, exclusiveMaximum: 1}", "1", []string{"/: 1 must be less than 1"}},
+		{"Arrays", "{prefixItems: [{type: string}], items: {type: integer}, uniqueItems: true, minItems: 4}", "[a, 1, 1]", []string{
+			"/: must have at least 4 items, but has 3",
+			"/: items 1 and 2 are equal",
+		}},
+		{"Contains", "{contains: {type: string}, maxContains: 1}", "[a, b, 1]", []string{"/: must contain at most 1 matching items, but contains 2"}},
+		{"One of", `
+oneOf:
+  - required: [all]
+  - required: [any]
+`, "{all: [], any: []}", []string{"/: value must match exactly one of the schemas in oneOf, but matches [0 1]"}},
+		{"If then else", `
+if: {properties: {kind: {const: text}}}
+then: {required: [text]}
+else: {required: [items]}
+`, "{kind: list}", []string{"/: missing property 'items'"}},
+		{"Unevaluated properties", `
+$ref: "#/definitions/base"
+properties:
+  extra: {type: boolean}
+unevaluatedProperties: false
+definitions:
+  base:
+    properties:
+      item: {}
+`, "{item: 1, extra: true, other: 2}", []string{"/: property 'other' is not allowed"}},
+		{"Unevaluated properties with any of", `
+anyOf:
+  - properties: {a: {type: string}}
+    required: [a]
+  - properties: {b: {type: string}}
+    required: [b]
+unevaluatedProperties: false
+`, "{a: x, b: 1}", []string{"/: property 'b' is not allowed"}},
+		{"Dependent required", "{dependentRequired: {credit card: [billing address]}}", "{credit card: 123}", []string{
+			"/: property 'billing address' is required when 'credit card' is present",
+		}},
+		{"Property names", "{propertyNames: {pattern: '^[a-z]+$'}}", "{ok: 1, Not/ok: 2}", []string{"/Not~1ok: 'Not/ok' does not match pattern '^[a-z]+$'"}},
+		{"Formats", `
+properties:
+  email: {format: email}
+  date: {format: date}
+  when: {format: date-time}
+  id: {format: uuid}
+  ip: {format: ipv4}
+  custom: {format: not-checked}
+`, "{email: not an email, date: 2024-02-30, when: '2024-02-01T10:00:00Z', id: 123e4567-e89b-12d3-a456-426614174000, ip: '::1', custom: x}", []string{
+			"/date: '2024-02-30' is not a valid date",
+			"/email: 'not an email' is not a valid email",
+			"/ip: '::1' is not a valid ipv4",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := NewLoader().Compile(parse(t, tt.schema), ".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := errorsOf(t, schema, tt.data)
+			if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Not equal:\n  Expected: %q\n  Actual:   %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReferencesAcrossFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/commands/Greet.schema.yaml": {Data: []byte(`
+$id: "https://example.com/v1/commands/Greet"
+properties:
+  person:
+    $ref: "/v1/types/Person.schema.yaml"
+  greeting:
+    $ref: "Common.schema.yaml#/definitions/Greeting"
+`)},
+		"schemas/commands/Common.schema.yaml": {Data: []byte(`
+definitions:
+  Greeting:
+    type: string
+    minLength: 1
+`)},
+		"schemas/types/Person.schema.yaml": {Data: []byte(`
+type: object
+required: [name]
+`)},
+	}
+	loader := NewLoader()
+	loader.Map("https://example.com/v1", fsys, "schemas")
+
+	schema, err := loader.Load("https://example.com/v1/commands/Greet.schema.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := errorsOf(t, schema, "{person: {}, greeting: ''}")
+	expected := []string{
+		"/greeting: length must be at least 1, but is 0",
+		"/person: missing property 'name'",
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Not equal:\n  Expected: %q\n  Actual:   %q", expected, actual)
+	}
+}
+
+// The extended tree of the JSON Schema specification: strict-tree refers to tree, and the $dynamicRef in tree refers
+// back to strict-tree when validation started there
+func TestDynamicReferences(t *testing.T) {
+	loader := NewLoader()
+	tree, err := loader.Compile(parse(t, `
+$id: "https://example.com/tree"
+$dynamicAnchor: node
+type: object
+properties:
+  data: true
+  children:
+    type: array
+    items:
+      $dynamicRef: "#node"
+`), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strictTree, err := loader.Compile(parse(t, `
+$id: "https://example.com/strict-tree"
+$dynamicAnchor: node
+$ref: tree
+unevaluatedProperties: false
+`), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := "{children: [{daat: 1}]}"
+	if actual := errorsOf(t, tree, data); actual != nil {
+		t.Errorf("Expected the tree to allow any property, got: %q", actual)
+	}
+	expected := []string{"/children/0: property 'daat' is not allowed"}
+	if actual := errorsOf(t, strictTree, data); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Not equal:\n  Expected: %q\n  Actual:   %q", expected, actual)
+	}
+}
+
+func TestInvalidSchema(t *testing.T) {
+	tests := map[string]string{
+		"{$ref: '#/definitions/missing'}": "can not resolve '/definitions/missing'",
+		"{pattern: '(?=x)'}":              "unsupported pattern '(?=x)'",
+		"{$ref: 'http://example.com/x'}":  "only local schemas are supported",
+	}
+	for source, expected := range tests {
+		schema, err := NewLoader().Compile(parse(t, source), ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = schema.Validate("x")
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s\n  Expected error containing: %s\n  Actual:                   %v", source, expected, err)
+		}
+	}
+}
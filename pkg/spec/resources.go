@@ -2,12 +2,27 @@ package spec
 
 import (
 	"embed"
+	"io/fs"
 )
 
-//go:embed instacli/instacli-spec/**
+// The scratchpad directory is not embedded: it contains file names that are
+// not valid in an embed.FS. The other directories are embedded with their
+// .instacli.yaml files.
+//
+//go:embed instacli/instacli-spec/README.md all:instacli/instacli-spec/cli all:instacli/instacli-spec/commands
+//go:embed all:instacli/instacli-spec/language
 var specFS embed.FS
 
 // GetSpecFile reads a file from the embedded Instacli spec filesystem
 func GetSpecFile(path string) ([]byte, error) {
 	return specFS.ReadFile("instacli/instacli-spec/" + path)
 }
+
+// FS returns the embedded Instacli spec, rooted at the spec directory
+func FS() fs.FS {
+	sub, err := fs.Sub(specFS, "instacli/instacli-spec")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,50 @@
+package spec
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sync"
+
+	"instacli/pkg/jsonschema"
+)
+
+// SchemaURIPrefix is the URI under which the command schemas of the spec are published
+const SchemaURIPrefix = "https://instacli.spec.it/v1/commands"
+
+var (
+	schemaLoader   *jsonschema.Loader
+	commandSchemas sync.Map
+	loaderOnce     sync.Once
+)
+
+// GetSchemaLoader returns a JSON schema loader that resolves references to the schemas in the embedded spec
+func GetSchemaLoader() *jsonschema.Loader {
+	loaderOnce.Do(func() {
+		schemaLoader = jsonschema.NewLoader()
+		schemaLoader.Map(SchemaURIPrefix, specFS, "instacli/instacli-spec/commands")
+	})
+	return schemaLoader
+}
+
+// GetCommandSchema returns the schema for the data of a command, for example "Assert equals" in namespace
+// "instacli/testing". It returns nil if the spec does not define a schema for the command.
+func GetCommandSchema(namespace, command string) (*jsonschema.Schema, error) {
+	key := namespace + "/" + command
+	if schema, ok := commandSchemas.Load(key); ok {
+		return schema.(*jsonschema.Schema), nil
+	}
+
+	file := namespace + "/schema/" + command + ".schema.yaml"
+	if _, err := fs.Stat(specFS, "instacli/instacli-spec/commands/"+file); err != nil {
+		commandSchemas.Store(key, (*jsonschema.Schema)(nil))
+		return nil, nil
+	}
+
+	schema, err := GetSchemaLoader().Load(SchemaURIPrefix + "/" + (&url.URL{Path: file}).EscapedPath())
+	if err != nil {
+		return nil, fmt.Errorf("error loading schema for %s: %w", command, err)
+	}
+	commandSchemas.Store(key, schema)
+	return schema, nil
+}
@@ -0,0 +1,50 @@
+package spec
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+
+	"instacli/pkg/jsonschema"
+)
+
+// TestCommandSchemas checks that all command schemas in the spec load and that their references resolve
+func TestCommandSchemas(t *testing.T) {
+	root := "instacli/instacli-spec/commands"
+	err := fs.WalkDir(specFS, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(file, ".schema.yaml") || path.Base(path.Dir(file)) != "schema" {
+			return err
+		}
+		namespace := strings.TrimPrefix(path.Dir(path.Dir(file)), root+"/")
+		command := strings.TrimSuffix(path.Base(file), ".schema.yaml")
+
+		t.Run(namespace+"/"+command, func(t *testing.T) {
+			schema, err := GetCommandSchema(namespace, command)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if schema == nil {
+				t.Fatal("schema not found")
+			}
+			for _, data := range []interface{}{"text", map[string]interface{}{}, []interface{}{1}} {
+				var validationErr *jsonschema.ValidationError
+				if err := schema.Validate(data); err != nil && !errors.As(err, &validationErr) {
+					t.Errorf("invalid schema: %v", err)
+				}
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMissingCommandSchema(t *testing.T) {
+	schema, err := GetCommandSchema("instacli/db", "Store")
+	if err != nil || schema != nil {
+		t.Errorf("expected no schema, got: %v, %v", schema, err)
+	}
+}
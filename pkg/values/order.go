@@ -0,0 +1,49 @@
+package values
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// keyOrders has the order in which the keys of objects were read or set, by the address of the map. Go maps do not
+// keep the order of their keys, but objects are written in the order of their source, like Instacli does. Objects
+// with their keys in sorted order are not recorded.
+var keyOrders sync.Map
+
+type keyOrder struct {
+	// object keeps the map alive, so its address is not taken by another map
+	object map[string]interface{}
+	keys   []string
+}
+
+// SetKeyOrder records the order of the keys of an object, so it is written with its keys in that order
+func SetKeyOrder(object map[string]interface{}, keys []string) {
+	if object == nil || sort.StringsAreSorted(keys) {
+		return
+	}
+	keyOrders.Store(reflect.ValueOf(object).Pointer(), &keyOrder{object: object, keys: keys})
+}
+
+// Keys returns the keys of an object in the order that was recorded for it, followed by the keys that were added
+// later, sorted
+func Keys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	seen := make(map[string]bool, len(object))
+	if order, ok := keyOrders.Load(reflect.ValueOf(object).Pointer()); ok {
+		for _, key := range order.(*keyOrder).keys {
+			if _, ok := object[key]; ok && !seen[key] {
+				keys = append(keys, key)
+				seen[key] = true
+			}
+		}
+	}
+	start := len(keys)
+	for key := range object {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys[start:])
+	return keys
+}
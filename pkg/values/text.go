@@ -0,0 +1,96 @@
+package values
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Text gives the canonical text of a value: text as it is, null, booleans and numbers the way YAML writes them, and
+// lists and objects as YAML
+func Text(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}, map[string]interface{}:
+		text, err := YAML(v)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimRight(text, "\n")
+	}
+	return ToNode(value).Value
+}
+
+// FormatNumber writes a float without an exponent, unless it is very large or small. A whole number keeps '.0', so
+// it is read back as a float.
+func FormatNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return ".nan"
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	}
+	abs := math.Abs(f)
+	if abs != 0 && (abs >= 1e21 || abs < 1e-6) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	text := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(text, ".") {
+		text += ".0"
+	}
+	return text
+}
+
+// YAML writes a value as a YAML document, with the scalars in canonical text, the keys of objects in the order they
+// were read or set, and an indent of two spaces
+func YAML(value interface{}) (string, error) {
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(ToNode(value)); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// ToNode converts a value to YAML. Values that are not in the model are encoded the way yaml.v3 does.
+func ToNode(value interface{}) *yaml.Node {
+	switch v := Normalize(value).(type) {
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}
+	case int:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(v)}
+	case float64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: FormatNumber(v)}
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			node.Content = append(node.Content, ToNode(item))
+		}
+		return node
+	case map[string]interface{}:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range Keys(v) {
+			node.Content = append(node.Content, ToNode(key), ToNode(v[key]))
+		}
+		return node
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: err.Error()}
+	}
+	return node
+}
@@ -0,0 +1,243 @@
+// Package values is the model of the data that scripts work with. Every value is one of:
+//
+//	nil                     null
+//	bool                    true or false
+//	int                     a whole number
+//	float64                 a number with a fraction, or one that was written as a float, like 1.0
+//	string                  text
+//	[]interface{}           a list of values
+//	map[string]interface{}  an object
+//
+// Data from YAML, JSON and databases is converted to this model, so a number is the same value wherever it came
+// from. Objects are written with their keys in the order they were read, see Keys.
+package values
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FromNode converts YAML to the model, using the tags of the nodes: a scalar is only a number or a boolean if YAML
+// reads it as one. Timestamps are kept as they are written. Aliases and merge keys ('<<') are followed.
+func FromNode(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return FromNode(node.Content[0])
+	case yaml.AliasNode:
+		return FromNode(node.Alias)
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := FromNode(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	case yaml.MappingNode:
+		object := make(map[string]interface{}, len(node.Content)/2)
+		keys, err := addEntries(object, node)
+		if err != nil {
+			return nil, err
+		}
+		SetKeyOrder(object, keys)
+		return object, nil
+	case yaml.ScalarNode:
+		return scalar(node)
+	}
+	return nil, fmt.Errorf("line %d: unknown YAML node", node.Line)
+}
+
+// addEntries adds the entries of a mapping to an object and returns their keys in order. Entries that are merged in
+// with '<<' do not replace the ones that the mapping has itself, and come after them.
+func addEntries(object map[string]interface{}, node *yaml.Node) ([]string, error) {
+	var keys []string
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, valueNode := node.Content[i], node.Content[i+1]
+		if key.ShortTag() == "!!merge" {
+			merged = append(merged, valueNode)
+			continue
+		}
+		keyValue, err := FromNode(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := FromNode(valueNode)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := object[Text(keyValue)]; !ok {
+			keys = append(keys, Text(keyValue))
+		}
+		object[Text(keyValue)] = value
+	}
+
+	for _, m := range merged {
+		if m.Kind == yaml.AliasNode {
+			m = m.Alias
+		}
+		sources := []*yaml.Node{m}
+		if m.Kind == yaml.SequenceNode {
+			sources = m.Content
+		}
+		for _, source := range sources {
+			if source.Kind == yaml.AliasNode {
+				source = source.Alias
+			}
+			if source.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("line %d: only objects can be merged with '<<'", source.Line)
+			}
+			entries := map[string]interface{}{}
+			mergedKeys, err := addEntries(entries, source)
+			if err != nil {
+				return nil, err
+			}
+			for _, key := range mergedKeys {
+				if _, ok := object[key]; !ok {
+					object[key] = entries[key]
+					keys = append(keys, key)
+				}
+			}
+		}
+	}
+	return keys, nil
+}
+
+func scalar(node *yaml.Node) (interface{}, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool", "!!int", "!!float", "!!binary":
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		return Normalize(value), nil
+	}
+	return node.Value, nil
+}
+
+// FromJSON converts JSON text to the model. Numbers without a fraction or exponent become int. Objects keep the
+// order of their keys.
+func FromJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return fromJSON(dec)
+}
+
+func fromJSON(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			item, err := fromJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		_, err := dec.Token()
+		return list, err
+	case json.Delim('{'):
+		object := map[string]interface{}{}
+		var keys []string
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := fromJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := object[key.(string)]; !ok {
+				keys = append(keys, key.(string))
+			}
+			object[key.(string)] = value
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		SetKeyOrder(object, keys)
+		return object, nil
+	}
+	return Normalize(token), nil
+}
+
+// Normalize converts Go values to the model: sized and unsigned integers become int, unless they are too large,
+// json.Number becomes int or float64, bytes become text and times are written in RFC 3339. Lists and objects are
+// converted in place. Values that the model does not know are returned as they are.
+func Normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, int, float64, string:
+		return v
+	case int8:
+		return int(v)
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case uint:
+		return unsigned(uint64(v))
+	case uint8:
+		return int(v)
+	case uint16:
+		return int(v)
+	case uint32:
+		return int(v)
+	case uint64:
+		return unsigned(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = Normalize(item)
+		}
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = Normalize(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[Text(Normalize(key))] = Normalize(item)
+		}
+		return object
+	}
+	return value
+}
+
+func unsigned(n uint64) interface{} {
+	if n > math.MaxInt {
+		return float64(n)
+	}
+	return int(n)
+}
@@ -0,0 +1,121 @@
+package values
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFromNode(t *testing.T) {
+	source := `
+null: ~
+empty:
+bool: true
+not bool: yes
+int: 0x1F
+big: 18446744073709551615
+float: 1.0
+exponent: 1e6
+quoted: "1"
+date: 2024-01-31
+base: &base {a: 1, b: 2}
+merged:
+  <<: *base
+  b: 3
+1: number key
+`
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(source), &node); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := FromNode(&node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"null":     nil,
+		"empty":    nil,
+		"bool":     true,
+		"not bool": "yes",
+		"int":      31,
+		"big":      float64(18446744073709551615),
+		"float":    1.0,
+		"exponent": 1e6,
+		"quoted":   "1",
+		"date":     "2024-01-31",
+		"base":     map[string]interface{}{"a": 1, "b": 2},
+		"merged":   map[string]interface{}{"a": 1, "b": 3},
+		"1":        "number key",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Not equal:\n  Expected: %#v\n  Actual:   %#v", expected, actual)
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{true, "true"},
+		{int64(42), "42"},
+		{1e6, "1000000.0"},
+		{1.5, "1.5"},
+		{0.000001, "0.000001"},
+		{1e21, "1e+21"},
+		{"text", "text"},
+		{[]interface{}{1.0, "2", nil}, "- 1.0\n- \"2\"\n- null"},
+		{map[string]interface{}{"b": 2.5, "a": "x"}, "a: x\nb: 2.5"},
+	}
+	for _, tt := range tests {
+		if actual := Text(tt.value); actual != tt.expected {
+			t.Errorf("%#v: expected %q, got %q", tt.value, tt.expected, actual)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	fromJSON, err := FromJSON([]byte(`{"count": 1, "price": 2.50, "tags": ["a"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromYAML := map[string]interface{}{"count": 1.0, "price": 2.5, "tags": []interface{}{"a"}}
+	if !Equal(fromJSON, fromYAML) {
+		t.Errorf("Expected %v to equal %v", fromJSON, fromYAML)
+	}
+	if Equal(1, "1") || Equal(true, "true") || Equal(nil, false) || Equal([]interface{}{1}, []interface{}{1, 2}) {
+		t.Error("Expected values of different types to differ")
+	}
+	if !Equal(int64(3), uint8(3)) {
+		t.Error("Expected numbers of different Go types to be equal")
+	}
+}
+
+func TestYAMLKeyOrder(t *testing.T) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte("Script info: {input: {name: x}}\nOutput: [{b: 1, a: 2}]\n"), &node); err != nil {
+		t.Fatal(err)
+	}
+	fromYAML, err := FromNode(&node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromYAML.(map[string]interface{})["Output"].([]interface{})[0].(map[string]interface{})["c"] = 3
+	fromJSON, err := FromJSON([]byte(`{"Script info": {"input": {"name": "x"}}, "Output": [{"b": 1, "a": 2, "c": 3}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Script info:\n  input:\n    name: x\nOutput:\n  - b: 1\n    a: 2\n    c: 3\n"
+	for _, value := range []interface{}{fromYAML, fromJSON} {
+		actual, err := YAML(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Errorf("Expected:\n%s\nActual:\n%s", expected, actual)
+		}
+	}
+}